@@ -0,0 +1,138 @@
+package jac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/jsonapi"
+	"github.com/pkg/errors"
+)
+
+// APIError is an error response of an API. Connector created with
+// WithAPIErrors returns it as an error instead of a slice of error objects
+type APIError struct {
+	// StatusCode is an HTTP status code of the response
+	StatusCode int
+	// Header contains response headers
+	Header http.Header
+	// Body is a raw response body
+	Body []byte
+	// Errors are error objects parsed from the response body according to JSON API
+	Errors []*jsonapi.ErrorObject
+	// RequestID is the request id echoed by the server or,
+	// if it has not echoed any, the one that was sent
+	RequestID string
+	// Attempts is a number of attempts made, more than 1 if the request was retried
+	Attempts int
+}
+
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("api responded with status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.RequestID != "" {
+		message = fmt.Sprintf("%s to request %s", message, e.RequestID)
+	}
+	if e.Attempts > 1 {
+		message = fmt.Sprintf("%s after %d attempts", message, e.Attempts)
+	}
+
+	for _, errObject := range e.Errors {
+		if errObject == nil {
+			continue
+		}
+
+		switch {
+		case errObject.Detail != "":
+			return fmt.Sprintf("%s: %s", message, errObject.Detail)
+		case errObject.Title != "":
+			return fmt.Sprintf("%s: %s", message, errObject.Title)
+		}
+	}
+
+	return message
+}
+
+// maxErrorDetailLength limits the length of a response body used as
+// a detail of a synthetic error object
+const maxErrorDetailLength = 512
+
+// decodeErrors returns error objects of an error response. If the body is empty,
+// is not JSON or contains no error objects, a synthetic error object is built
+// from the status code and the body
+func decodeErrors(status int, header http.Header, raw []byte) []*jsonapi.ErrorObject {
+	if len(bytes.TrimSpace(raw)) != 0 && isJSON(header.Get("Content-Type")) {
+		var errsPayload jsonapi.ErrorsPayload
+		if err := json.Unmarshal(raw, &errsPayload); err == nil && len(errsPayload.Errors) != 0 {
+			return errsPayload.Errors
+		}
+	}
+
+	detail := strings.TrimSpace(string(raw))
+	if len(detail) > maxErrorDetailLength {
+		// cutting at a rune start, so a multibyte character is not split
+		cut := maxErrorDetailLength
+		for cut > 0 && !utf8.RuneStart(detail[cut]) {
+			cut--
+		}
+		detail = detail[:cut] + "..."
+	}
+
+	return []*jsonapi.ErrorObject{{
+		Status: strconv.Itoa(status),
+		Title:  http.StatusText(status),
+		Detail: detail,
+	}}
+}
+
+// isJSON reports whether content type is a JSON one. Missing content type
+// is considered JSON, as some services do not set it
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" ||
+		mediaType == jsonapi.MediaType ||
+		strings.HasSuffix(mediaType, "+json")
+}
+
+// HasStatus reports whether err is an APIError with given status code
+func HasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// IsBadRequest reports whether err is an APIError with 400 Bad Request status
+func IsBadRequest(err error) bool {
+	return HasStatus(err, http.StatusBadRequest)
+}
+
+// IsUnauthorized reports whether err is an APIError with 401 Unauthorized status
+func IsUnauthorized(err error) bool {
+	return HasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an APIError with 403 Forbidden status
+func IsForbidden(err error) bool {
+	return HasStatus(err, http.StatusForbidden)
+}
+
+// IsNotFound reports whether err is an APIError with 404 Not Found status
+func IsNotFound(err error) bool {
+	return HasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an APIError with 409 Conflict status
+func IsConflict(err error) bool {
+	return HasStatus(err, http.StatusConflict)
+}
@@ -0,0 +1,143 @@
+package jac
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/jsonapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStatusServer returns server that responds with JSON API error
+// depending on the request path, or with 204 for "/ok"
+func newStatusServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusNoContent)
+		case "/missing":
+			w.Header().Set("X-Trace", "trace-id")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[{"status":"404","title":"Not Found","detail":"user is missing"}]}`))
+		case "/conflict":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"errors":[{"status":"409","title":"Conflict"}]}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"errors":[{"status":"500"}]}`))
+		}
+	}))
+}
+
+func TestJacer_APIErrors(t *testing.T) {
+	testServer := newStatusServer()
+	defer testServer.Close()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		apiErrs, err := NewJac(testServer.URL).Get(RequestParams{Endpoint: "missing"}, nil)
+		assert.Nil(t, err)
+		assert.Len(t, apiErrs, 1)
+	})
+	t.Run("returned as APIError", func(t *testing.T) {
+		apiErrs, err := NewJac(testServer.URL, WithAPIErrors()).Get(RequestParams{Endpoint: "missing"}, nil)
+		assert.Nil(t, apiErrs)
+		assert.True(t, IsNotFound(err))
+		assert.False(t, IsConflict(err))
+
+		ctx := ContextWithRequestID(context.Background(), "req-1")
+		_, err = NewJac(testServer.URL, WithAPIErrors()).Get(RequestParams{Endpoint: "missing", Context: ctx}, nil)
+		assert.EqualError(t, err, "api responded with status 404 Not Found to request req-1: user is missing")
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "trace-id", apiErr.Header.Get("X-Trace"))
+		assert.Contains(t, string(apiErr.Body), "user is missing")
+		assert.Equal(t, "Not Found", apiErr.Errors[0].Title)
+	})
+	t.Run("status helpers", func(t *testing.T) {
+		_, err := NewJac(testServer.URL, WithAPIErrors()).Delete(RequestParams{Endpoint: "conflict"})
+		assert.True(t, IsConflict(err))
+		assert.False(t, IsUnauthorized(err))
+		assert.False(t, IsNotFound(errors.New("not an api error")))
+	})
+}
+
+func TestJacer_Exists(t *testing.T) {
+	testServer := newStatusServer()
+	defer testServer.Close()
+
+	for _, testJac := range []Jac{
+		NewJac(testServer.URL),
+		NewJac(testServer.URL, WithAPIErrors()),
+		NewJac(testServer.URL, WithExistsStrategy(ExistsByHead)),
+	} {
+		exists, err := testJac.Exists(RequestParams{Endpoint: "ok"})
+		assert.Nil(t, err)
+		assert.True(t, exists)
+
+		notExists, err := testJac.NotExists(RequestParams{Endpoint: "missing"})
+		assert.Nil(t, err)
+		assert.True(t, notExists)
+
+		_, err = testJac.Exists(RequestParams{Endpoint: "broken"})
+		assert.True(t, HasStatus(err, http.StatusInternalServerError))
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	decode := func(status int, contentType string, raw []byte) []*jsonapi.ErrorObject {
+		return decodeErrors(status, http.Header{"Content-Type": {contentType}}, raw)
+	}
+
+	t.Run("json api errors", func(t *testing.T) {
+		errs := decode(http.StatusBadRequest, "application/vnd.api+json", []byte(`{"errors":[{"status":"400","detail":"bad"},{"status":"400","detail":"worse"}]}`))
+		assert.Len(t, errs, 2)
+		assert.Equal(t, "worse", errs[1].Detail)
+	})
+	t.Run("html proxy page", func(t *testing.T) {
+		errs := decode(http.StatusBadGateway, "text/html; charset=utf-8", []byte("<html><body>502 Bad Gateway</body></html>"))
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "502", errs[0].Status)
+		assert.Equal(t, "Bad Gateway", errs[0].Title)
+		assert.Equal(t, "<html><body>502 Bad Gateway</body></html>", errs[0].Detail)
+	})
+	t.Run("empty body", func(t *testing.T) {
+		errs := decode(http.StatusNotFound, "", nil)
+		assert.Len(t, errs, 1)
+		assert.Equal(t, "404", errs[0].Status)
+		assert.Equal(t, "Not Found", errs[0].Title)
+		assert.Empty(t, errs[0].Detail)
+	})
+	t.Run("json without errors", func(t *testing.T) {
+		errs := decode(http.StatusInternalServerError, "application/json", []byte(`{"message":"oops"}`))
+		assert.Len(t, errs, 1)
+		assert.Equal(t, `{"message":"oops"}`, errs[0].Detail)
+	})
+	t.Run("long body is truncated", func(t *testing.T) {
+		errs := decode(http.StatusInternalServerError, "text/plain", bytes.Repeat([]byte("x"), 2*maxErrorDetailLength))
+		assert.Len(t, errs[0].Detail, maxErrorDetailLength+len("..."))
+	})
+	t.Run("long body is truncated at rune boundary", func(t *testing.T) {
+		// 3-byte runes, so the limit falls inside one of them
+		errs := decode(http.StatusInternalServerError, "text/plain", bytes.Repeat([]byte("€"), maxErrorDetailLength))
+		assert.True(t, utf8.ValidString(errs[0].Detail), "expected valid UTF-8 detail")
+		assert.Equal(t, strings.Repeat("€", maxErrorDetailLength/3)+"...", errs[0].Detail)
+	})
+}
+
+func TestJacer_Exists_BareNotFound(t *testing.T) {
+	testServer := httptest.NewServer(http.NotFoundHandler())
+	defer testServer.Close()
+
+	exists, err := NewJac(testServer.URL).Exists(RequestParams{Endpoint: "users/1"})
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,198 @@
+package jac
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AuthTypeBearer selects static bearer token authentication
+	AuthTypeBearer = "bearer"
+	// AuthTypeFile selects bearer token authentication with a token read from file
+	AuthTypeFile = "file"
+	// AuthTypeBasic selects basic authentication
+	AuthTypeBasic = "basic"
+	// AuthTypeAPIKey selects authentication with an API key sent in a header
+	AuthTypeAPIKey = "api_key"
+
+	// defaultAPIKeyHeader is a header used by API key authentication if none is specified
+	defaultAPIKeyHeader = "X-API-Key"
+)
+
+// Authenticator adds credentials to every request sent by a connector
+type Authenticator interface {
+	// Authenticate modifies request by adding credentials to it.
+	// Returned error aborts the request.
+	Authenticate(r *http.Request) error
+}
+
+// AuthenticatorFunc is an adapter to allow the use of ordinary
+// functions as Authenticator
+type AuthenticatorFunc func(r *http.Request) error
+
+// Authenticate calls f(r)
+func (f AuthenticatorFunc) Authenticate(r *http.Request) error {
+	return f(r)
+}
+
+// AuthMiddleware returns Middleware that authenticates every call with auth.
+// Headers set per request override credentials. If a call is rejected with
+// 401 and auth implements Refresher, credentials are refreshed and the call
+// is sent once again
+func AuthMiddleware(auth Authenticator) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			authenticated, err := authenticate(auth, call)
+			if err != nil {
+				return nil, err
+			}
+
+			response, err := next.Do(authenticated)
+			if err != nil {
+				return nil, err
+			}
+
+			refresher, ok := auth.(Refresher)
+			if !ok || response.StatusCode != http.StatusUnauthorized {
+				return response, nil
+			}
+
+			discardResponse(response)
+
+			if err = refresher.Refresh(authenticated.Request); err != nil {
+				return nil, errors.Wrap(err, "failed to refresh credentials")
+			}
+
+			authenticated, err = authenticate(auth, call)
+			if err != nil {
+				return nil, err
+			}
+
+			return next.Do(authenticated)
+		})
+	}
+}
+
+// authenticate returns a copy of call with credentials added to the request
+func authenticate(auth Authenticator, call *Call) (*Call, error) {
+	authenticated, err := call.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = auth.Authenticate(authenticated.Request); err != nil {
+		return nil, errors.Wrap(err, "failed to authenticate request")
+	}
+	// request headers are restored after authentication,
+	// so credentials can be overridden per request
+	setHeader(authenticated.Request.Header, call.header)
+
+	return authenticated, nil
+}
+
+// NewBearerAuth returns Authenticator that sets static bearer token
+func NewBearerAuth(token string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		setBearer(r, token)
+		return nil
+	})
+}
+
+// NewBasicAuth returns Authenticator that uses basic authentication
+func NewBasicAuth(username, password string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		r.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// NewAPIKeyAuth returns Authenticator that sends key in the specified header.
+// If header is empty, X-API-Key is used
+func NewAPIKeyAuth(header, key string) Authenticator {
+	if header == "" {
+		header = defaultAPIKeyHeader
+	}
+
+	return AuthenticatorFunc(func(r *http.Request) error {
+		r.Header.Set(header, key)
+		return nil
+	})
+}
+
+// NewCallbackAuth returns Authenticator that sets bearer token
+// returned by tokenFn. tokenFn receives the request context
+func NewCallbackAuth(tokenFn func(ctx context.Context) (string, error)) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		token, err := tokenFn(r.Context())
+		if err != nil {
+			return errors.Wrap(err, "failed to get token")
+		}
+
+		setBearer(r, token)
+		return nil
+	})
+}
+
+// fileAuth is an Authenticator that reads bearer token from file
+// and re-reads it every time the file changes
+type fileAuth struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	token   string
+}
+
+// NewFileAuth returns Authenticator that sets bearer token read from file at path.
+// The token is re-read when the file modification time or size changes,
+// so rotated tokens are picked up without restart
+func NewFileAuth(path string) Authenticator {
+	return &fileAuth{path: path}
+}
+
+func (a *fileAuth) Authenticate(r *http.Request) error {
+	token, err := a.getToken()
+	if err != nil {
+		return errors.Wrap(err, "failed to get token from file")
+	}
+
+	setBearer(r, token)
+	return nil
+}
+
+// getToken returns cached token or reads it from file if it has changed
+func (a *fileAuth) getToken() (string, error) {
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to stat token file")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && info.ModTime().Equal(a.modTime) && info.Size() == a.size {
+		return a.token, nil
+	}
+
+	raw, err := os.ReadFile(a.path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read token file")
+	}
+
+	a.token = strings.TrimSpace(string(raw))
+	a.modTime = info.ModTime()
+	a.size = info.Size()
+
+	return a.token, nil
+}
+
+func setBearer(r *http.Request, token string) {
+	r.Header.Set("Authorization", "Bearer "+token)
+}
@@ -0,0 +1,120 @@
+package jac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/distributed_lab/kit/kv"
+)
+
+// newHeaderEchoServer returns server that responds with received value of header
+func newHeaderEchoServer(header string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`"` + r.Header.Get(header) + `"`))
+	}))
+}
+
+func getAuthHeader(t *testing.T, server *httptest.Server, auth Authenticator) string {
+	var value string
+	_, err := NewJac(server.URL, WithAuthenticator(auth)).Get(RequestParams{}, &value)
+	assert.Nil(t, err)
+
+	return value
+}
+
+func TestAuthenticators(t *testing.T) {
+	authServer := newHeaderEchoServer("Authorization")
+	defer authServer.Close()
+
+	t.Run("bearer", func(t *testing.T) {
+		assert.Equal(t, "Bearer my-token", getAuthHeader(t, authServer, NewBearerAuth("my-token")))
+	})
+	t.Run("basic", func(t *testing.T) {
+		assert.Equal(t, "Basic dXNlcjpwYXNz", getAuthHeader(t, authServer, NewBasicAuth("user", "pass")))
+	})
+	t.Run("callback", func(t *testing.T) {
+		auth := NewCallbackAuth(func(ctx context.Context) (string, error) {
+			return "callback-token", nil
+		})
+		assert.Equal(t, "Bearer callback-token", getAuthHeader(t, authServer, auth))
+	})
+	t.Run("callback error aborts request", func(t *testing.T) {
+		auth := NewCallbackAuth(func(ctx context.Context) (string, error) {
+			return "", errors.New("no token")
+		})
+		_, err := NewJac(authServer.URL, WithAuthenticator(auth)).Get(RequestParams{}, nil)
+		assert.NotNil(t, err)
+	})
+	t.Run("per request header overrides authenticator", func(t *testing.T) {
+		var value string
+		_, err := NewJac(authServer.URL, WithAuthenticator(NewBearerAuth("my-token"))).Get(
+			RequestParams{Header: map[string]string{"Authorization": "Bearer other-token"}},
+			&value,
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, "Bearer other-token", value)
+	})
+	t.Run("file is re-read on change", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		assert.Nil(t, os.WriteFile(path, []byte("first-token\n"), 0600))
+
+		auth := NewFileAuth(path)
+		assert.Equal(t, "Bearer first-token", getAuthHeader(t, authServer, auth))
+
+		assert.Nil(t, os.WriteFile(path, []byte("second-token\n"), 0600))
+		assert.Nil(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+		assert.Equal(t, "Bearer second-token", getAuthHeader(t, authServer, auth))
+	})
+
+	apiKeyServer := newHeaderEchoServer("X-Service-Key")
+	defer apiKeyServer.Close()
+
+	t.Run("api key", func(t *testing.T) {
+		assert.Equal(t, "my-key", getAuthHeader(t, apiKeyServer, NewAPIKeyAuth("X-Service-Key", "my-key")))
+	})
+}
+
+func TestJacer_ConfigureJac_Auth(t *testing.T) {
+	t.Run("jwt shorthand", func(t *testing.T) {
+		authServer := newHeaderEchoServer("Authorization")
+		defer authServer.Close()
+
+		var value string
+		_, err := NewJACer(configGetter(authServer.URL, map[string]interface{}{"jwt": "my-jwt"})).
+			ConfigureJac(nil).
+			Get(RequestParams{}, &value)
+		assert.Nil(t, err)
+		assert.Equal(t, "Bearer my-jwt", value)
+	})
+	t.Run("auth block overrides jwt", func(t *testing.T) {
+		apiKeyServer := newHeaderEchoServer("X-API-Key")
+		defer apiKeyServer.Close()
+
+		var value string
+		_, err := NewJACer(configGetter(apiKeyServer.URL, map[string]interface{}{
+			"jwt":  "my-jwt",
+			"auth": map[string]interface{}{"type": AuthTypeAPIKey, "key": "my-key"},
+		})).ConfigureJac(nil).Get(RequestParams{}, &value)
+		assert.Nil(t, err)
+		assert.Equal(t, "my-key", value)
+	})
+}
+
+// configGetter returns kv.Getter that returns config with given url and extra values
+func configGetter(url string, values map[string]interface{}) kv.Getter {
+	return kv.GetterFunc(func(key string) (map[string]interface{}, error) {
+		raw := map[string]interface{}{"url": url}
+		for k, v := range values {
+			raw[k] = v
+		}
+
+		return raw, nil
+	})
+}
@@ -0,0 +1,288 @@
+package jac
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is returned without sending a request when circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is a state of a circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets all requests through and counts failures
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all requests with ErrCircuitOpen
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of probe requests through
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerSettings configures a circuit breaker. Transport errors and 5xx
+// responses are counted as failures. Zero fields are replaced with the ones
+// from DefaultBreakerSettings
+type BreakerSettings struct {
+	// FailureRatio is a ratio of failed requests that opens the breaker
+	FailureRatio float64
+	// MinRequests is a minimum number of requests in the interval
+	// before FailureRatio is checked
+	MinRequests int
+	// Interval is how often failure counts are reset while the breaker is closed
+	Interval time.Duration
+	// OpenDuration is how long the breaker stays open before letting probes through
+	OpenDuration time.Duration
+	// HalfOpenProbes is a number of probe requests let through in half-open
+	// state. The breaker closes when all of them succeed
+	HalfOpenProbes int
+	// PerEndpoint enables a separate breaker for every RequestParams.Endpoint.
+	// Endpoint templates are used as is, so "users/{id}" has a single breaker
+	PerEndpoint bool
+	// OnStateChange is called when a breaker changes its state. Key is
+	// the endpoint for per-endpoint breakers and an empty string otherwise.
+	// It is called synchronously, so it must not block
+	OnStateChange func(key string, from, to BreakerState)
+}
+
+// DefaultBreakerSettings returns settings of a breaker that opens for 30 seconds
+// when at least half of at least 10 requests in a minute have failed
+func DefaultBreakerSettings() BreakerSettings {
+	return BreakerSettings{
+		FailureRatio:   0.5,
+		MinRequests:    10,
+		Interval:       time.Minute,
+		OpenDuration:   30 * time.Second,
+		HalfOpenProbes: 1,
+	}
+}
+
+// withDefaults replaces zero fields of settings with default ones
+func (s BreakerSettings) withDefaults() BreakerSettings {
+	defaults := DefaultBreakerSettings()
+
+	if s.FailureRatio == 0 {
+		s.FailureRatio = defaults.FailureRatio
+	}
+	if s.MinRequests == 0 {
+		s.MinRequests = defaults.MinRequests
+	}
+	if s.Interval == 0 {
+		s.Interval = defaults.Interval
+	}
+	if s.OpenDuration == 0 {
+		s.OpenDuration = defaults.OpenDuration
+	}
+	if s.HalfOpenProbes == 0 {
+		s.HalfOpenProbes = defaults.HalfOpenProbes
+	}
+
+	return s
+}
+
+// CircuitBreakerMiddleware returns Middleware that sends calls through
+// a circuit breaker configured with settings. Open breaker fails calls fast
+// with ErrCircuitOpen. Breakers are shared by all connectors using the middleware
+func CircuitBreakerMiddleware(settings BreakerSettings) Middleware {
+	breakers := newBreakers(settings.withDefaults())
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			breaker := breakers.get(call.Endpoint)
+			generation, err := breaker.allow()
+			if err != nil {
+				return nil, err
+			}
+
+			response, err := next.Do(call)
+			// call cancelled by the caller tells nothing about the upstream
+			// health, so it is not counted at all, unlike client timeouts
+			if err != nil && classifyCallError(call, err) == ErrorClassCanceled {
+				breaker.skip(generation)
+				return nil, err
+			}
+			breaker.done(generation, response, err)
+
+			return response, err
+		})
+	}
+}
+
+// breakers holds circuit breakers of a connector
+type breakers struct {
+	settings BreakerSettings
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func newBreakers(settings BreakerSettings) *breakers {
+	return &breakers{
+		settings: settings,
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+// get returns a breaker responsible for the endpoint
+func (b *breakers) get(endpoint string) *circuitBreaker {
+	key := ""
+	if b.settings.PerEndpoint {
+		key = endpoint
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	breaker, ok := b.breakers[key]
+	if !ok {
+		breaker = &circuitBreaker{
+			key:      key,
+			settings: b.settings,
+			expiry:   time.Now().Add(b.settings.Interval),
+		}
+		b.breakers[key] = breaker
+	}
+
+	return breaker
+}
+
+// circuitBreaker is a single circuit breaker
+type circuitBreaker struct {
+	key      string
+	settings BreakerSettings
+
+	mu         sync.Mutex
+	state      BreakerState
+	generation uint64
+	// expiry is when counts are reset in closed state
+	// or when the breaker becomes half-open in open state
+	expiry    time.Time
+	requests  int
+	failures  int
+	successes int
+}
+
+// allow reports whether a request can be sent. It returns ErrCircuitOpen
+// if not, or a generation that must be passed to done otherwise
+func (b *circuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh(time.Now())
+
+	switch b.state {
+	case BreakerOpen:
+		return 0, ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.requests >= b.settings.HalfOpenProbes {
+			return 0, ErrCircuitOpen
+		}
+	}
+
+	b.requests++
+	return b.generation, nil
+}
+
+// skip forgets a request allowed in the given generation without recording its result
+func (b *circuitBreaker) skip(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh(time.Now())
+	if generation == b.generation {
+		b.requests--
+	}
+}
+
+// done records the result of a request allowed in the given generation
+func (b *circuitBreaker) done(generation uint64, response *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.refresh(now)
+
+	// the result belongs to a state that has already passed
+	if generation != b.generation {
+		return
+	}
+
+	success := err == nil && response.StatusCode < http.StatusInternalServerError
+
+	if !success {
+		b.failures++
+	}
+
+	switch b.state {
+	case BreakerClosed:
+		if b.requests >= b.settings.MinRequests &&
+			float64(b.failures)/float64(b.requests) >= b.settings.FailureRatio {
+			b.setState(BreakerOpen, now)
+		}
+	case BreakerHalfOpen:
+		if !success {
+			b.setState(BreakerOpen, now)
+			return
+		}
+
+		b.successes++
+		if b.successes >= b.settings.HalfOpenProbes {
+			b.setState(BreakerClosed, now)
+		}
+	}
+}
+
+// refresh resets counts or moves open breaker to half-open state when expiry has passed
+func (b *circuitBreaker) refresh(now time.Time) {
+	if now.Before(b.expiry) {
+		return
+	}
+
+	switch b.state {
+	case BreakerClosed:
+		b.resetCounts(now)
+	case BreakerOpen:
+		b.setState(BreakerHalfOpen, now)
+	}
+}
+
+// setState changes the state of the breaker and starts a new generation
+func (b *circuitBreaker) setState(state BreakerState, now time.Time) {
+	from := b.state
+	b.state = state
+	b.resetCounts(now)
+
+	if state == BreakerOpen {
+		b.expiry = now.Add(b.settings.OpenDuration)
+	}
+	if state == BreakerHalfOpen {
+		b.expiry = time.Time{}
+	}
+
+	if b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(b.key, from, state)
+	}
+}
+
+// resetCounts starts a new generation with zero counts
+func (b *circuitBreaker) resetCounts(now time.Time) {
+	b.generation++
+	b.requests, b.failures, b.successes = 0, 0, 0
+	b.expiry = now.Add(b.settings.Interval)
+}
@@ -0,0 +1,120 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJacer_CircuitBreaker(t *testing.T) {
+	var failing int32 = 1
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 && r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"errors":[{"status":"500"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer testServer.Close()
+
+	var transitions []BreakerState
+	testJac := NewJac(testServer.URL, WithCircuitBreaker(BreakerSettings{
+		MinRequests:  2,
+		OpenDuration: 50 * time.Millisecond,
+		PerEndpoint:  true,
+		OnStateChange: func(key string, from, to BreakerState) {
+			assert.Equal(t, "failing", key)
+			transitions = append(transitions, to)
+		},
+	}))
+
+	get := func(endpoint string) error {
+		_, err := testJac.Get(RequestParams{Endpoint: endpoint}, nil)
+		return err
+	}
+
+	t.Run("opens after failures", func(t *testing.T) {
+		assert.Nil(t, get("failing"))
+		assert.Nil(t, get("failing"))
+		assert.True(t, errors.Is(get("failing"), ErrCircuitOpen), "expected circuit to be open")
+	})
+	t.Run("other endpoints are not affected", func(t *testing.T) {
+		assert.Nil(t, get("healthy"))
+	})
+	t.Run("reopens after failed probe", func(t *testing.T) {
+		time.Sleep(60 * time.Millisecond)
+		assert.Nil(t, get("failing"))
+		assert.True(t, errors.Is(get("failing"), ErrCircuitOpen), "expected circuit to be open")
+	})
+	t.Run("closes after successful probe", func(t *testing.T) {
+		atomic.StoreInt32(&failing, 0)
+		time.Sleep(60 * time.Millisecond)
+		assert.Nil(t, get("failing"))
+		assert.Nil(t, get("failing"))
+	})
+
+	assert.Equal(t, []BreakerState{
+		BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed,
+	}, transitions)
+}
+
+func TestJacer_CircuitBreakerTimeouts(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer testServer.Close()
+
+	settings := BreakerSettings{MinRequests: 2, OpenDuration: time.Minute}
+
+	t.Run("client timeouts open breaker", func(t *testing.T) {
+		testJac := NewJac(testServer.URL, WithTimeout(20*time.Millisecond), WithCircuitBreaker(settings))
+		for i := 0; i < 2; i++ {
+			_, err := testJac.Get(RequestParams{}, nil)
+			assert.NotNil(t, err)
+			assert.False(t, errors.Is(err, ErrCircuitOpen))
+		}
+
+		_, err := testJac.Get(RequestParams{}, nil)
+		assert.True(t, errors.Is(err, ErrCircuitOpen), "expected circuit to be open, got %v", err)
+	})
+	t.Run("cancelled calls are not counted", func(t *testing.T) {
+		testJac := NewJac(testServer.URL, WithCircuitBreaker(settings))
+		for i := 0; i < 3; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			_, err := testJac.Get(RequestParams{Context: ctx}, nil)
+			cancel()
+			assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected deadline exceeded error, got %v", err)
+		}
+	})
+}
+
+func TestCircuitBreaker_HalfOpenProbes(t *testing.T) {
+	breaker := newBreakers(BreakerSettings{MinRequests: 1, HalfOpenProbes: 2, OpenDuration: time.Millisecond}.withDefaults()).get("")
+	failed := &http.Response{StatusCode: http.StatusBadGateway}
+	succeeded := &http.Response{StatusCode: http.StatusOK}
+
+	generation, err := breaker.allow()
+	assert.Nil(t, err)
+	breaker.done(generation, failed, nil)
+	assert.Equal(t, BreakerOpen, breaker.state)
+
+	time.Sleep(2 * time.Millisecond)
+	first, err := breaker.allow()
+	assert.Nil(t, err)
+	second, err := breaker.allow()
+	assert.Nil(t, err)
+	_, err = breaker.allow()
+	assert.Equal(t, ErrCircuitOpen, err, "expected only 2 probes to be let through")
+
+	breaker.done(first, succeeded, nil)
+	assert.Equal(t, BreakerHalfOpen, breaker.state)
+	breaker.done(second, succeeded, nil)
+	assert.Equal(t, BreakerClosed, breaker.state)
+}
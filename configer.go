@@ -1,7 +1,13 @@
 package jac
 
 import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
 	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 	"gitlab.com/distributed_lab/figure"
 	"gitlab.com/distributed_lab/kit/comfig"
 	"gitlab.com/distributed_lab/kit/kv"
@@ -11,6 +17,17 @@ var (
 	// jacDefaultConfigKey is a key in .config file corresponding
 	// to the Jac configuration
 	jacDefaultConfigKey = "jac"
+
+	// configHooks are figure hooks of JacConfig types missing in figure.BaseHooks
+	configHooks = figure.Hooks{
+		"map[string]string": func(value interface{}) (reflect.Value, error) {
+			result, err := cast.ToStringMapStringE(value)
+			if err != nil {
+				return reflect.Value{}, errors.Wrap(err, "failed to parse map[string]string")
+			}
+			return reflect.ValueOf(result), nil
+		},
+	}
 )
 
 // jacer is a struct implementing JACer interface
@@ -22,6 +39,245 @@ type jacer struct {
 // JacConfig contains configurable data of a Jac
 type JacConfig struct {
 	URL string `fig:"url,required"`
+	// JWT is a shorthand for bearer authentication with a static token.
+	// It is ignored if Auth.Type is set
+	JWT *string `fig:"jwt"`
+	// Auth selects and configures request authentication
+	Auth AuthConfig `fig:"auth"`
+	// Retry configures retries of failed requests
+	Retry RetryConfig `fig:"retry"`
+	// Breaker configures a circuit breaker
+	Breaker BreakerConfig `fig:"breaker"`
+	// APIErrors enables returning API errors as *APIError
+	APIErrors bool `fig:"api_errors"`
+	// ExistsStrategy is either "get" or "head", "get" by default
+	ExistsStrategy string `fig:"exists_strategy"`
+	// Timeout is a time limit for a whole request. Zero means no timeout
+	Timeout time.Duration `fig:"timeout"`
+	// MaxIdleConns limits idle connections across all hosts
+	MaxIdleConns int `fig:"max_idle_conns"`
+	// MaxIdleConnsPerHost limits idle connections kept per host
+	MaxIdleConnsPerHost int `fig:"max_idle_conns_per_host"`
+	// MaxConnsPerHost limits the total number of connections per host
+	MaxConnsPerHost int `fig:"max_conns_per_host"`
+	// TLSHandshakeTimeout is a time limit for TLS handshake
+	TLSHandshakeTimeout time.Duration `fig:"tls_handshake_timeout"`
+	// Headers are sent with every request, e.g. tenant or Accept
+	Headers map[string]string `fig:"headers"`
+	// UserAgent overrides the default "jac/<version> (<service name>)" User-Agent
+	UserAgent string `fig:"user_agent"`
+	// ServiceName is sent in the default User-Agent, executable name by default
+	ServiceName string `fig:"service_name"`
+	// RequestIDHeader is a header request id is sent in, X-Request-ID by default
+	RequestIDHeader string `fig:"request_id_header"`
+	// Timing enables capturing of a timing breakdown of every request
+	Timing bool `fig:"timing"`
+}
+
+// AuthConfig contains configurable data of a connector authentication.
+// Type is one of AuthTypeBearer, AuthTypeFile, AuthTypeBasic, AuthTypeAPIKey or AuthTypeOAuth2.
+// Callback authentication can only be set in code via WithAuthenticator
+type AuthConfig struct {
+	Type         string   `fig:"type"`
+	Token        string   `fig:"token"`
+	File         string   `fig:"file"`
+	Username     string   `fig:"username"`
+	Password     string   `fig:"password"`
+	Header       string   `fig:"header"`
+	Key          string   `fig:"key"`
+	TokenURL     string   `fig:"token_url"`
+	ClientID     string   `fig:"client_id"`
+	ClientSecret string   `fig:"client_secret"`
+	Scopes       []string `fig:"scopes"`
+}
+
+// Authenticator returns Authenticator corresponding to the config
+// or nil if authentication is not configured
+func (c AuthConfig) Authenticator() (Authenticator, error) {
+	switch c.Type {
+	case "":
+		return nil, nil
+	case AuthTypeBearer:
+		return NewBearerAuth(c.Token), nil
+	case AuthTypeFile:
+		if c.File == "" {
+			return nil, errors.New("file is required for file authentication")
+		}
+		return NewFileAuth(c.File), nil
+	case AuthTypeBasic:
+		return NewBasicAuth(c.Username, c.Password), nil
+	case AuthTypeAPIKey:
+		return NewAPIKeyAuth(c.Header, c.Key), nil
+	case AuthTypeOAuth2:
+		if c.TokenURL == "" {
+			return nil, errors.New("token_url is required for oauth2 authentication")
+		}
+		return NewOAuth2Auth(OAuth2Config{
+			TokenURL:     c.TokenURL,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			Scopes:       c.Scopes,
+		}), nil
+	default:
+		return nil, errors.Errorf("unknown auth type %q", c.Type)
+	}
+}
+
+// RetryConfig contains configurable data of a RetryPolicy.
+// Retries are enabled only if MaxAttempts is greater than 1,
+// other zero fields are replaced with the ones from DefaultRetryPolicy
+type RetryConfig struct {
+	MaxAttempts int           `fig:"max_attempts"`
+	BaseBackoff time.Duration `fig:"base_backoff"`
+	MaxBackoff  time.Duration `fig:"max_backoff"`
+	Statuses    []int64       `fig:"statuses"`
+	Methods     []string      `fig:"methods"`
+	Errors      []string      `fig:"errors"`
+}
+
+// Policy returns RetryPolicy corresponding to the config
+// or nil if retries are disabled
+func (c RetryConfig) Policy() *RetryPolicy {
+	if c.MaxAttempts <= 1 {
+		return nil
+	}
+
+	policy := RetryPolicy{
+		MaxAttempts: c.MaxAttempts,
+		BaseBackoff: c.BaseBackoff,
+		MaxBackoff:  c.MaxBackoff,
+	}
+	for _, method := range c.Methods {
+		policy.Methods = append(policy.Methods, strings.ToUpper(method))
+	}
+	for _, status := range c.Statuses {
+		policy.Statuses = append(policy.Statuses, int(status))
+	}
+	for _, class := range c.Errors {
+		policy.Errors = append(policy.Errors, ErrorClass(class))
+	}
+
+	policy = policy.withDefaults()
+	return &policy
+}
+
+// BreakerConfig contains configurable data of a circuit breaker.
+// Zero fields are replaced with the ones from DefaultBreakerSettings
+type BreakerConfig struct {
+	Enabled        bool          `fig:"enabled"`
+	FailureRatio   float64       `fig:"failure_ratio"`
+	MinRequests    int           `fig:"min_requests"`
+	Interval       time.Duration `fig:"interval"`
+	OpenDuration   time.Duration `fig:"open_duration"`
+	HalfOpenProbes int           `fig:"half_open_probes"`
+	PerEndpoint    bool          `fig:"per_endpoint"`
+}
+
+// Settings returns BreakerSettings corresponding to the config
+// or nil if circuit breaker is disabled
+func (c BreakerConfig) Settings() *BreakerSettings {
+	if !c.Enabled {
+		return nil
+	}
+
+	settings := BreakerSettings{
+		FailureRatio:   c.FailureRatio,
+		MinRequests:    c.MinRequests,
+		Interval:       c.Interval,
+		OpenDuration:   c.OpenDuration,
+		HalfOpenProbes: c.HalfOpenProbes,
+		PerEndpoint:    c.PerEndpoint,
+	}.withDefaults()
+
+	return &settings
+}
+
+// Validate is called by figure after the config is parsed
+func (c JacConfig) Validate() error {
+	switch ExistsStrategy(c.ExistsStrategy) {
+	case "", ExistsByGet, ExistsByHead:
+	default:
+		return errors.Errorf("unknown exists strategy %q", c.ExistsStrategy)
+	}
+
+	for _, class := range c.Retry.Errors {
+		switch ErrorClass(class) {
+		case ErrorClassTimeout, ErrorClassConnection, ErrorClassCanceled, ErrorClassOther:
+		default:
+			return errors.Errorf("unknown retry error class %q", class)
+		}
+	}
+
+	_, err := c.Auth.Authenticator()
+	return err
+}
+
+// Options returns connector options corresponding to the config.
+// Each connector gets its own transport, so connection pools are not shared.
+// Use WithTransport to replace it with a custom one
+func (c JacConfig) Options() []Option {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.MaxIdleConns != 0 {
+		transport.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost != 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	if c.MaxConnsPerHost != 0 {
+		transport.MaxConnsPerHost = c.MaxConnsPerHost
+	}
+	if c.TLSHandshakeTimeout != 0 {
+		transport.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+	}
+
+	opts := []Option{WithTransport(transport)}
+	if c.Timeout != 0 {
+		opts = append(opts, WithTimeout(c.Timeout))
+	}
+
+	// config is validated when it is figured out, so error is always nil here
+	auth, _ := c.Auth.Authenticator()
+	if auth == nil && c.JWT != nil {
+		auth = NewBearerAuth(*c.JWT)
+	}
+	if auth != nil {
+		opts = append(opts, WithAuthenticator(auth))
+	}
+
+	if policy := c.Retry.Policy(); policy != nil {
+		opts = append(opts, WithRetryPolicy(*policy))
+	}
+	if settings := c.Breaker.Settings(); settings != nil {
+		opts = append(opts, WithCircuitBreaker(*settings))
+	}
+	if c.APIErrors {
+		opts = append(opts, WithAPIErrors())
+	}
+	if c.ExistsStrategy != "" {
+		opts = append(opts, WithExistsStrategy(ExistsStrategy(c.ExistsStrategy)))
+	}
+
+	if len(c.Headers) != 0 {
+		header := make(http.Header, len(c.Headers))
+		for key, value := range c.Headers {
+			header.Set(key, value)
+		}
+		opts = append(opts, WithHeaders(header))
+	}
+	if c.UserAgent != "" {
+		opts = append(opts, WithUserAgent(c.UserAgent))
+	}
+	if c.ServiceName != "" {
+		opts = append(opts, WithServiceName(c.ServiceName))
+	}
+	if c.RequestIDHeader != "" {
+		opts = append(opts, WithRequestIDHeader(c.RequestIDHeader))
+	}
+	if c.Timing {
+		opts = append(opts, WithTiming())
+	}
+
+	return opts
 }
 
 // NewJACer returns an instance of JACer structure that configures Jac
@@ -43,7 +299,7 @@ func (c *jacer) GetJacConfig(configKey *string) JacConfig {
 		raw    = kv.MustGetStringMap(c.getter, *configKey)
 	)
 
-	if err := figure.Out(&config).From(raw).Please(); err != nil {
+	if err := figure.Out(&config).With(figure.BaseHooks, configHooks).From(raw).Please(); err != nil {
 		panic(errors.Wrap(err, "failed to figure out jac"))
 	}
 
@@ -53,7 +309,8 @@ func (c *jacer) GetJacConfig(configKey *string) JacConfig {
 // ConfigureJac returns configured Jac based on a provided config from kv.Getter
 //   - configKey is a key in .config file corresponding to the Jac configuration.
 //     If nil, then default key is used
-func (c *jacer) ConfigureJac(configKey *string) Jac {
+//   - opts are applied after the ones built from config, so they take precedence
+func (c *jacer) ConfigureJac(configKey *string, opts ...Option) Jac {
 	cfg := c.GetJacConfig(configKey)
-	return NewJac(cfg.URL)
+	return NewJac(cfg.URL, append(cfg.Options(), opts...)...)
 }
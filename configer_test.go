@@ -1,7 +1,9 @@
 package jac
 
 import (
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gitlab.com/distributed_lab/kit/kv"
@@ -17,8 +19,10 @@ func TestJacer_GetJacConfig(t *testing.T) {
 		myJacer := NewJACer(kv.NewViperFile(jacTestConfigKey1))
 		jacCfg := myJacer.GetJacConfig(nil)
 
+		jwt := "my-coolest-jwt"
 		assert.Equal(t, JacConfig{
 			URL: "http://localhost:8000",
+			JWT: &jwt,
 		}, jacCfg)
 	})
 
@@ -27,11 +31,59 @@ func TestJacer_GetJacConfig(t *testing.T) {
 		jacCfgKey := "my-connector-name"
 		jacCfg := myJacer.GetJacConfig(&jacCfgKey)
 
+		jwt := "my-worst-jwt"
 		assert.Equal(t, JacConfig{
 			URL: "http://localhost:8001",
+			JWT: &jwt,
+			Auth: AuthConfig{
+				Type:   AuthTypeAPIKey,
+				Header: "X-Service-Key",
+				Key:    "my-worst-key",
+			},
+			Timeout:             5 * time.Second,
+			MaxIdleConns:        50,
+			MaxConnsPerHost:     10,
+			TLSHandshakeTimeout: 2 * time.Second,
+			ServiceName:         "my-service",
+			Headers: map[string]string{
+				"x-tenant": "acme",
+				"accept":   "application/vnd.api+json",
+			},
 		}, jacCfg)
 	})
 
+	t.Run("using test-config-2.yaml with retry and breaker", func(t *testing.T) {
+		myJacer := NewJACer(kv.NewViperFile(jacTestConfigKey2))
+		jacCfgKey := "my-resilient-connector"
+		jacCfg := myJacer.GetJacConfig(&jacCfgKey)
+
+		assert.Equal(t, RetryConfig{
+			MaxAttempts: 5,
+			BaseBackoff: 50 * time.Millisecond,
+			MaxBackoff:  time.Second,
+			Statuses:    []int64{502, 503},
+			Methods:     []string{"get", "put"},
+			Errors:      []string{"timeout"},
+		}, jacCfg.Retry)
+		assert.Equal(t, &RetryPolicy{
+			MaxAttempts: 5,
+			BaseBackoff: 50 * time.Millisecond,
+			MaxBackoff:  time.Second,
+			Statuses:    []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+			Methods:     []string{http.MethodGet, http.MethodPut},
+			Errors:      []ErrorClass{ErrorClassTimeout},
+		}, jacCfg.Retry.Policy())
+
+		assert.Equal(t, &BreakerSettings{
+			FailureRatio:   0.3,
+			MinRequests:    20,
+			Interval:       30 * time.Second,
+			OpenDuration:   10 * time.Second,
+			HalfOpenProbes: 2,
+			PerEndpoint:    true,
+		}, jacCfg.Breaker.Settings())
+	})
+
 	t.Run("using non-existent config: expect panic", func(t *testing.T) {
 		defer func() {
 			if r := recover(); r == nil {
@@ -42,4 +94,71 @@ func TestJacer_GetJacConfig(t *testing.T) {
 		myWrongJacer := NewJACer(kv.NewViperFile("some-non-existent-config.yaml"))
 		_ = myWrongJacer.GetJacConfig(nil)
 	})
+
+	t.Run("using unknown retry error class: expect panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected panic")
+			}
+		}()
+
+		myWrongJacer := NewJACer(kv.GetterFunc(func(key string) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"url":   "http://localhost:8000",
+				"retry": map[string]interface{}{"max_attempts": 3, "errors": []string{"timeouts"}},
+			}, nil
+		}))
+		_ = myWrongJacer.GetJacConfig(nil)
+	})
+
+	t.Run("using unknown auth type: expect panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected panic")
+			}
+		}()
+
+		myWrongJacer := NewJACer(kv.GetterFunc(func(key string) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"url":  "http://localhost:8000",
+				"auth": map[string]interface{}{"type": "unknown"},
+			}, nil
+		}))
+		_ = myWrongJacer.GetJacConfig(nil)
+	})
+}
+
+func TestJacConfig_Options(t *testing.T) {
+	jacCfgKey := "my-connector-name"
+	cfg := NewJACer(kv.NewViperFile(jacTestConfigKey2)).GetJacConfig(&jacCfgKey)
+
+	client := newOptions(cfg.Options()...).httpClient()
+	assert.Equal(t, 5*time.Second, client.Timeout)
+
+	transport, ok := client.Transport.(*http.Transport)
+	assert.True(t, ok, "expected *http.Transport")
+	assert.NotSame(t, http.DefaultTransport, transport, "expected connector to have its own transport")
+	assert.Equal(t, 50, transport.MaxIdleConns)
+	assert.Equal(t, 10, transport.MaxConnsPerHost)
+	assert.Equal(t, 2*time.Second, transport.TLSHandshakeTimeout)
+
+	assert.Equal(t, http.Header{
+		"X-Tenant":   {"acme"},
+		"Accept":     {"application/vnd.api+json"},
+		"User-Agent": {"jac/" + Version + " (my-service)"},
+	}, newOptions(cfg.Options()...).defaultHeader())
+}
+
+func TestJacConfig_OptionsWithHTTPClient(t *testing.T) {
+	jacCfgKey := "my-connector-name"
+	cfg := NewJACer(kv.NewViperFile(jacTestConfigKey2)).GetJacConfig(&jacCfgKey)
+
+	custom := &http.Client{Transport: &http.Transport{MaxIdleConns: 7}, Timeout: time.Minute}
+	client := newOptions(append(cfg.Options(), WithHTTPClient(custom))...).httpClient()
+	assert.Same(t, custom.Transport, client.Transport, "expected custom transport to be kept")
+	assert.Equal(t, time.Minute, client.Timeout)
+
+	client = newOptions(append(cfg.Options(), WithHTTPClient(custom), WithTimeout(time.Second))...).httpClient()
+	assert.Same(t, custom.Transport, client.Transport)
+	assert.Equal(t, time.Second, client.Timeout)
 }
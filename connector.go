@@ -2,26 +2,50 @@ package jac
 
 import (
 	"bytes"
-	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/google/jsonapi"
-	"gitlab.com/distributed_lab/logan/v3"
-	"gitlab.com/distributed_lab/logan/v3/errors"
+	"github.com/pkg/errors"
 )
 
 // jac is a structure that implements Jac interface
 type jac struct {
 	BaseUrl string
-	client  *http.Client
+	// doer sends calls through the middleware chain
+	doer      Doer
+	apiErrors bool
+	// header contains headers sent with every request
+	header http.Header
+	// requestIDHeader is a header request id is sent in, empty if disabled
+	requestIDHeader string
+	// timing enables capturing of Timing of calls
+	timing bool
+
+	existsStrategy ExistsStrategy
 }
 
-// NewJac returns new jac instance that implements Jac interface
-func NewJac(baseUrl string) Jac {
-	return &jac{baseUrl, http.DefaultClient}
+// NewJac returns new jac instance that implements Jac interface.
+// Connector behaviour can be customized with opts, for example:
+//
+//	NewJac(url, WithTimeout(5*time.Second), WithTransport(rt))
+func NewJac(baseUrl string, opts ...Option) Jac {
+	o := newOptions(opts...)
+
+	return &jac{
+		BaseUrl:   baseUrl,
+		doer:      o.doer(),
+		apiErrors: o.apiErrors,
+		header:    o.defaultHeader(),
+
+		requestIDHeader: o.requestIDHeader,
+		timing:          o.timing,
+
+		existsStrategy: o.existsStrategy,
+	}
 }
 
 func (c *jac) Get(params RequestParams, destination any) ([]*jsonapi.ErrorObject, error) {
@@ -36,29 +60,59 @@ func (c *jac) Patch(params RequestParams, destination any) ([]*jsonapi.ErrorObje
 	return c.perform(params.addMethod(http.MethodPatch), destination)
 }
 
+func (c *jac) Put(params RequestParams, destination any) ([]*jsonapi.ErrorObject, error) {
+	return c.perform(params.addMethod(http.MethodPut), destination)
+}
+
 func (c *jac) Delete(params RequestParams) ([]*jsonapi.ErrorObject, error) {
 	return c.perform(params.addMethod(http.MethodDelete), nil)
 }
 
+func (c *jac) Head(params RequestParams) ([]*jsonapi.ErrorObject, error) {
+	return c.perform(params.addMethod(http.MethodHead), nil)
+}
+
+func (c *jac) Options(params RequestParams, destination any) ([]*jsonapi.ErrorObject, error) {
+	return c.perform(params.addMethod(http.MethodOptions), destination)
+}
+
+func (c *jac) Request(method string, params RequestParams, destination any) ([]*jsonapi.ErrorObject, error) {
+	return c.perform(params.addMethod(method), destination)
+}
+
+func (c *jac) Do(method string, params RequestParams, destination any) (*Response, error) {
+	response, err := c.performRequest(params.addMethod(method), destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiErr := response.APIError(); apiErr != nil {
+		return response, apiErr
+	}
+
+	return response, nil
+}
+
 func (c *jac) Exists(params RequestParams) (bool, error) {
-	jsonErrs, err := c.Get(params, nil)
+	method := http.MethodGet
+	if c.existsStrategy == ExistsByHead {
+		method = http.MethodHead
+	}
+
+	response, err := c.performRequest(params.addMethod(method), nil)
 	if err != nil {
 		return false, errors.Wrap(err, "failed to validate if object exists")
 	}
 
-	for _, jsonErr := range jsonErrs {
-		if jsonErr != nil {
-			if jsonErr.Status == fmt.Sprintf("%v", http.StatusNotFound) {
-				return false, err
-			}
-		}
-
-		return false, errors.New(
-			fmt.Sprintf("unexpected error with status code %s and detail %s", jsonErr.Status, jsonErr.Detail),
-		)
+	apiErr := response.APIError()
+	if apiErr == nil {
+		return true, nil
+	}
+	if apiErr.StatusCode == http.StatusNotFound {
+		return false, nil
 	}
 
-	return true, err
+	return false, apiErr
 }
 
 func (c *jac) NotExists(params RequestParams) (bool, error) {
@@ -66,91 +120,188 @@ func (c *jac) NotExists(params RequestParams) (bool, error) {
 	return exists == false, err
 }
 
-// perform performs a request based on given parameters
-// func (c *jac) perform(method, endpoint string, data []byte, destination any) ([]*jsonapi.ErrorObject, error) {
+// perform performs a request based on given parameters and returns API errors
+// either as a slice of error objects or as an APIError, if connector is configured so
 func (c *jac) perform(params RequestParams, destination any) ([]*jsonapi.ErrorObject, error) {
-	response, err := c.do(params)
+	response, err := c.performRequest(params, destination)
+	if err != nil {
+		return nil, err
+	}
+
+	apiErr := response.APIError()
+	if apiErr == nil {
+		return nil, nil
+	}
+	if c.apiErrors {
+		return nil, apiErr
+	}
+
+	return apiErr.Errors, nil
+}
+
+// performRequest performs a request based on given parameters and reads its
+// response. Body of a successful response is decoded into destination if it is not nil
+func (c *jac) performRequest(params RequestParams, destination any) (*Response, error) {
+	start := time.Now()
+
+	params, err := params.encodeInput()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode input")
+	}
+
+	params, err = params.expandPath()
 	if err != nil {
+		return nil, errors.Wrap(err, "failed to expand endpoint")
+	}
+
+	params, err = params.encodePayload()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode payload")
+	}
+
+	params, requestID := c.withRequestID(params)
+
+	var timing *Timing
+	if c.timing {
+		timing = new(Timing)
+	}
+
+	response, attempts, err := c.do(params, timing)
+	if err != nil {
+		if requestID != "" {
+			return nil, errors.Wrapf(err, "failed to send request %s", requestID)
+		}
 		return nil, errors.Wrap(err, "failed to send request")
 	}
 
-	errsPayload, err := c.readResponseBody(response, destination)
+	result, err := c.readResponse(response)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read response body")
 	}
+	result.Duration = time.Since(start)
+	result.Timing = timing
+	result.Attempts = attempts
+	result.RequestID = requestID
+	// server may assign its own id, which is the one to look for in its logs
+	if echoed := response.Header.Get(c.requestIDHeader); requestID != "" && echoed != "" {
+		result.RequestID = echoed
+	}
 
-	if errsPayload != nil {
-		return errsPayload.Errors, err
+	if destination == nil || result.StatusCode >= http.StatusBadRequest {
+		return result, nil
 	}
 
-	return nil, nil
+	if err = result.Decode(destination); err != nil {
+		return nil, errors.Wrap(err, "failed to decode response body")
+	}
+
+	return result, nil
 }
 
 // resolveEndpoint forms url by adding endpoint to base url.
-// It ignores possible errors
+// Endpoint is always a path, so it can never replace the base url
 func (c *jac) resolveEndpoint(endpoint string) (string, error) {
 	result, err := url.JoinPath(c.BaseUrl, endpoint)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to join path", logan.F{
-			"base":     c.BaseUrl,
-			"endpoint": endpoint,
-		})
+		return "", errors.Wrapf(err, "failed to join path %q and %q", c.BaseUrl, endpoint)
 	}
 
 	return result, nil
 }
 
+// checkLink returns an error unless link points to the scheme and host of
+// the base url, so connector headers and credentials are never sent elsewhere
+func (c *jac) checkLink(link string) error {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse link %q", link)
+	}
+
+	base, err := url.Parse(c.BaseUrl)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse base url %q", c.BaseUrl)
+	}
+
+	if !strings.EqualFold(parsed.Scheme, base.Scheme) || !strings.EqualFold(parsed.Host, base.Host) {
+		return errors.Errorf("link %q points outside of base url %q", link, c.BaseUrl)
+	}
+
+	return nil
+}
+
 // do sends specified request to specified endpoint based on received method and data
-func (c *jac) do(params RequestParams) (*http.Response, error) {
-	endpoint, err := c.resolveEndpoint(params.Endpoint)
+// through the middleware chain. Request is bound to params context, so cancelling
+// it aborts the in-flight call. Timing of every attempt is captured into timing unless it is nil.
+// It returns a number of attempts made along with the response
+func (c *jac) do(params RequestParams, timing *Timing) (*http.Response, int, error) {
+	request, err := c.newRequest(params)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to resolve endpoint")
+		return nil, 0, errors.Wrap(err, "failed to build request")
+	}
+
+	attempts := 1
+	response, err := c.doer.Do(&Call{
+		Request:  request,
+		Endpoint: params.Endpoint,
+		Attempt:  1,
+		Timing:   timing,
+		header:   params.requestHeader(),
+		attempts: &attempts,
+	})
+
+	return response, attempts, err
+}
+
+// newRequest creates a request based on received params
+func (c *jac) newRequest(params RequestParams) (*http.Request, error) {
+	endpoint := params.link
+	if endpoint != "" {
+		if err := c.checkLink(endpoint); err != nil {
+			return nil, errors.Wrap(err, "failed to follow link")
+		}
+	} else {
+		resolved, err := c.resolveEndpoint(params.path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve endpoint")
+		}
+		endpoint = resolved
 	}
 
-	request, err := http.NewRequest(params.method, endpoint, bytes.NewReader(params.Body))
+	request, err := http.NewRequestWithContext(params.ctx(), params.method, endpoint, bytes.NewReader(params.Body))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create a request")
 	}
 
+	// default headers are added first, so request headers override them
+	setHeader(request.Header, params.defaultHeader)
+	setHeader(request.Header, c.header)
 	request = params.addRequestHeaders(request)
-	request = params.addRequestQuery(request)
+	// link already contains all query parameters
+	if params.link == "" {
+		request = params.addRequestQuery(request)
+	}
 
-	return c.client.Do(request)
+	return request, nil
 }
 
-// readResponseBody reads response body into destination and returns
-// respErrsPayload in case of API errors with status code higher than 400
-// or err in case of some other problem happened
-func (c *jac) readResponseBody(response *http.Response, destination any) (
-	respErrsPayload *jsonapi.ErrorsPayload,
-	err error,
-) {
+// readResponse reads response body and closes it
+func (c *jac) readResponse(response *http.Response) (result *Response, err error) {
 	// closing response body
 	defer func(Body io.ReadCloser) {
-		if tempErr := Body.Close(); tempErr != nil {
+		if tempErr := Body.Close(); tempErr != nil && err == nil {
 			err = tempErr
 		}
 	}(response.Body)
 
-	// parsing response
 	raw, err := io.ReadAll(response.Body)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read response body")
 	}
 
-	// if status code is equal or higher than BadRequest
-	// we are unmarshalling into errors payload
-	if response.StatusCode >= http.StatusBadRequest {
-		var errsPayload jsonapi.ErrorsPayload
-		err = json.Unmarshal(raw, &errsPayload)
-		return &errsPayload, err
-	}
-
-	// if destination is nil, we do not read response body
-	if destination == nil {
-		return
-	}
-
-	err = json.Unmarshal(raw, &destination)
-	return nil, err
+	return &Response{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       raw,
+		URL:        response.Request.URL,
+	}, nil
 }
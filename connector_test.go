@@ -1,11 +1,14 @@
 package jac
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/stretchr/testify/assert"
@@ -71,6 +74,28 @@ func newTestRouter() chi.Router {
 
 				ape.Render(w, patchTestResponse{Bar: request.Foo})
 			})
+			r.Put("/", func(w http.ResponseWriter, r *http.Request) {
+				var request patchTestRequestBody
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					ape.RenderErr(w, problems.BadRequest(err)...)
+				}
+
+				ape.Render(w, patchTestResponse{Bar: request.Foo})
+			})
+			r.Head("/", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			r.Options("/", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Allow", "GET, POST, PUT, PATCH, HEAD, OPTIONS")
+				ape.Render(w, getTestResponse{Foo: "options"})
+			})
+			r.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+					ape.Render(w, getTestResponse{Foo: "slow"})
+				}
+			})
 		})
 	})
 
@@ -147,3 +172,104 @@ func TestJacer_Post(t *testing.T) {
 		assert.Equal(t, testMultiplyResponse, testMultiplyExpectedResponse)
 	})
 }
+
+func TestJacer_Put(t *testing.T) {
+	testServer := httptest.NewServer(testRouter)
+	defer testServer.Close()
+
+	requestAsBytes, err := json.Marshal(patchTestRequestBody{Foo: "baz"})
+	assert.Nil(t, err, "expected nil error when marshalling a request body")
+
+	var testResponse patchTestResponse
+	_, err = getTestJac(testServer).Put(RequestParams{Endpoint: testUrlBase, Body: requestAsBytes}, &testResponse)
+	assert.Nil(t, err, "expected nil error when unmarshalling put response")
+	assert.Equal(t, patchTestResponse{Bar: "baz"}, testResponse)
+}
+
+func TestJacer_HeadOptions(t *testing.T) {
+	testServer := httptest.NewServer(testRouter)
+	defer testServer.Close()
+
+	testJac := getTestJac(testServer)
+
+	t.Run("head request", func(t *testing.T) {
+		apiErrs, err := testJac.Head(RequestParams{Endpoint: testUrlBase})
+		assert.Nil(t, err)
+		assert.Empty(t, apiErrs)
+	})
+	t.Run("options request", func(t *testing.T) {
+		var testResponse getTestResponse
+		_, err := testJac.Options(RequestParams{Endpoint: testUrlBase}, &testResponse)
+		assert.Nil(t, err)
+		assert.Equal(t, getTestResponse{Foo: "options"}, testResponse)
+	})
+	t.Run("generic request", func(t *testing.T) {
+		var testResponse getTestResponse
+		_, err := testJac.Request(http.MethodGet, RequestParams{Endpoint: testUrlBase}, &testResponse)
+		assert.Nil(t, err)
+		assert.Equal(t, getTestResponse{Foo: "bar"}, testResponse)
+	})
+}
+
+func TestJacer_Context(t *testing.T) {
+	testServer := httptest.NewServer(testRouter)
+	defer testServer.Close()
+
+	testJac := getTestJac(testServer)
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		var testResponse getTestResponse
+		_, err := testJac.Get(
+			RequestParams{Endpoint: fmt.Sprintf("%s/%s", testUrlBase, "slow")}.WithContext(ctx),
+			&testResponse,
+		)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected deadline exceeded error, got %v", err)
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := testJac.Get(RequestParams{Endpoint: fmt.Sprintf("%s/%s", testUrlBase, "slow"), Context: ctx}, nil)
+		assert.True(t, errors.Is(err, context.Canceled), "expected context canceled error, got %v", err)
+	})
+}
+
+type countingTransport struct {
+	calls int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.calls++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestJacer_Options(t *testing.T) {
+	testServer := httptest.NewServer(testRouter)
+	defer testServer.Close()
+
+	t.Run("timeout", func(t *testing.T) {
+		testJac := NewJac(testServer.URL, WithTimeout(50*time.Millisecond))
+		_, err := testJac.Get(RequestParams{Endpoint: fmt.Sprintf("%s/%s", testUrlBase, "slow")}, nil)
+		assert.NotNil(t, err, "expected timeout error")
+	})
+	t.Run("transport", func(t *testing.T) {
+		transport := &countingTransport{}
+		testJac := NewJac(testServer.URL, WithTransport(transport))
+		_, err := testJac.Get(RequestParams{Endpoint: testUrlBase}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, transport.calls)
+	})
+	t.Run("client is not modified", func(t *testing.T) {
+		client := &http.Client{}
+		_ = NewJac(testServer.URL, WithHTTPClient(client), WithTimeout(time.Second))
+		assert.Zero(t, client.Timeout)
+	})
+	t.Run("nil client is the default one", func(t *testing.T) {
+		testJac := NewJac(testServer.URL, WithHTTPClient(nil))
+		_, err := testJac.Get(RequestParams{Endpoint: testUrlBase}, nil)
+		assert.Nil(t, err)
+	})
+}
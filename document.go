@@ -0,0 +1,114 @@
+package jac
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/google/jsonapi"
+	"github.com/pkg/errors"
+)
+
+// Document contains top-level links and meta of a JSON:API document
+type Document struct {
+	Links jsonapi.Links
+	Meta  jsonapi.Meta
+}
+
+// Link returns href of a top-level link with given name, e.g. "next".
+// Both string and object link forms are supported
+func (d *Document) Link(name string) (string, bool) {
+	switch link := d.Links[name].(type) {
+	case string:
+		return link, link != ""
+	case map[string]interface{}:
+		href, ok := link["href"].(string)
+		return href, ok && href != ""
+	default:
+		return "", false
+	}
+}
+
+func (c *jac) GetResource(params RequestParams, model any) (*Document, error) {
+	response, err := c.Do(http.MethodGet, params.withDefaultHeader("Accept", jsonapi.MediaType), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := response.DecodeResource(model)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode resource")
+	}
+
+	return document, nil
+}
+
+func (c *jac) GetCollection(params RequestParams, models any) (*Document, error) {
+	response, err := c.Do(http.MethodGet, params.withDefaultHeader("Accept", jsonapi.MediaType), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := response.DecodeCollection(models)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode collection")
+	}
+
+	return document, nil
+}
+
+// decodeResource unmarshals single resource document into model
+// which must be a pointer to a struct with jsonapi tags
+func decodeResource(raw []byte, model any) (*Document, error) {
+	var payload jsonapi.OnePayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal document")
+	}
+
+	if err := jsonapi.UnmarshalPayload(bytes.NewReader(raw), model); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal resource")
+	}
+
+	return newDocument(payload.Links, payload.Meta), nil
+}
+
+// decodeCollection unmarshals collection document into models
+// which must be a pointer to a slice of pointers to structs with jsonapi tags
+func decodeCollection(raw []byte, models any) (*Document, error) {
+	slice := reflect.ValueOf(models)
+	if slice.Kind() != reflect.Pointer || slice.Elem().Kind() != reflect.Slice ||
+		slice.Elem().Type().Elem().Kind() != reflect.Pointer {
+		return nil, errors.Errorf("expected pointer to a slice of pointers, got %T", models)
+	}
+
+	var payload jsonapi.ManyPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal document")
+	}
+
+	items, err := jsonapi.UnmarshalManyPayload(bytes.NewReader(raw), slice.Elem().Type().Elem())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal resources")
+	}
+
+	result := reflect.MakeSlice(slice.Elem().Type(), 0, len(items))
+	for _, item := range items {
+		result = reflect.Append(result, reflect.ValueOf(item))
+	}
+	slice.Elem().Set(result)
+
+	return newDocument(payload.Links, payload.Meta), nil
+}
+
+func newDocument(links *jsonapi.Links, meta *jsonapi.Meta) *Document {
+	document := &Document{}
+	if links != nil {
+		document.Links = *links
+	}
+	if meta != nil {
+		document.Meta = *meta
+	}
+
+	return document
+}
@@ -0,0 +1,159 @@
+package jac
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/jsonapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAuthor struct {
+	ID   string `jsonapi:"primary,authors"`
+	Name string `jsonapi:"attr,name"`
+}
+
+type testArticle struct {
+	ID     string      `jsonapi:"primary,articles"`
+	Title  string      `jsonapi:"attr,title"`
+	Author *testAuthor `jsonapi:"relation,author"`
+}
+
+const (
+	testArticleDocument = `{
+		"data": {
+			"type": "articles", "id": "1", "attributes": {"title": "JSON:API"},
+			"relationships": {"author": {"data": {"type": "authors", "id": "9"}}}
+		},
+		"included": [{"type": "authors", "id": "9", "attributes": {"name": "Dan"}}],
+		"links": {"self": "/articles/1"},
+		"meta": {"version": "v1"}
+	}`
+	testArticlesDocument = `{
+		"data": [
+			{"type": "articles", "id": "1", "attributes": {"title": "first"},
+			 "relationships": {"author": {"data": {"type": "authors", "id": "9"}}}},
+			{"type": "articles", "id": "2", "attributes": {"title": "second"}}
+		],
+		"included": [{"type": "authors", "id": "9", "attributes": {"name": "Dan"}}],
+		"links": {"next": {"href": "/articles?page[number]=2"}},
+		"meta": {"total": 3}
+	}`
+)
+
+func newDocumentServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != jsonapi.MediaType {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+
+		w.Header().Set("Content-Type", jsonapi.MediaType)
+		switch r.URL.Path {
+		case "/articles/1":
+			_, _ = w.Write([]byte(testArticleDocument))
+		case "/articles":
+			_, _ = w.Write([]byte(testArticlesDocument))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestJacer_GetResource(t *testing.T) {
+	testServer := newDocumentServer()
+	defer testServer.Close()
+
+	testJac := NewJac(testServer.URL)
+
+	t.Run("resource with included relationship", func(t *testing.T) {
+		var article testArticle
+		document, err := testJac.GetResource(RequestParams{Endpoint: "articles/1"}, &article)
+		assert.Nil(t, err)
+		assert.Equal(t, testArticle{ID: "1", Title: "JSON:API", Author: &testAuthor{ID: "9", Name: "Dan"}}, article)
+		assert.Equal(t, "v1", document.Meta["version"])
+
+		self, ok := document.Link("self")
+		assert.True(t, ok)
+		assert.Equal(t, "/articles/1", self)
+	})
+	t.Run("missing resource", func(t *testing.T) {
+		var article testArticle
+		_, err := testJac.GetResource(RequestParams{Endpoint: "articles/2"}, &article)
+		assert.True(t, IsNotFound(err))
+	})
+}
+
+func TestJacer_GetCollection(t *testing.T) {
+	testServer := newDocumentServer()
+	defer testServer.Close()
+
+	testJac := NewJac(testServer.URL)
+
+	t.Run("collection with included relationship", func(t *testing.T) {
+		var articles []*testArticle
+		document, err := testJac.GetCollection(RequestParams{Endpoint: "articles"}, &articles)
+		assert.Nil(t, err)
+		assert.Equal(t, []*testArticle{
+			{ID: "1", Title: "first", Author: &testAuthor{ID: "9", Name: "Dan"}},
+			{ID: "2", Title: "second"},
+		}, articles)
+		assert.Equal(t, float64(3), document.Meta["total"])
+
+		next, ok := document.Link("next")
+		assert.True(t, ok)
+		assert.Equal(t, "/articles?page[number]=2", next)
+
+		_, ok = document.Link("prev")
+		assert.False(t, ok)
+	})
+	t.Run("invalid destination", func(t *testing.T) {
+		var articles []testArticle
+		_, err := testJac.GetCollection(RequestParams{Endpoint: "articles"}, &articles)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestJacer_Payload(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != jsonapi.MediaType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		// echoing request document back
+		w.Header().Set("Content-Type", jsonapi.MediaType)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer testServer.Close()
+
+	testJac := NewJac(testServer.URL)
+
+	t.Run("single resource", func(t *testing.T) {
+		article := &testArticle{ID: "1", Title: "JSON:API", Author: &testAuthor{ID: "9", Name: "Dan"}}
+		response, err := testJac.Do(http.MethodPost, RequestParams{Endpoint: "articles", Payload: article}, nil)
+		assert.Nil(t, err)
+
+		var created testArticle
+		_, err = response.DecodeResource(&created)
+		assert.Nil(t, err)
+		assert.Equal(t, *article, created)
+	})
+	t.Run("slice of resources", func(t *testing.T) {
+		articles := []*testArticle{{ID: "1", Title: "first"}, {ID: "2", Title: "second"}}
+		response, err := testJac.Do(http.MethodPost, RequestParams{Endpoint: "articles", Payload: articles}, nil)
+		assert.Nil(t, err)
+
+		var created []*testArticle
+		_, err = response.DecodeCollection(&created)
+		assert.Nil(t, err)
+		assert.Equal(t, articles, created)
+	})
+	t.Run("body and payload together", func(t *testing.T) {
+		_, err := testJac.Post(RequestParams{Body: []byte("{}"), Payload: &testArticle{}}, nil)
+		assert.NotNil(t, err)
+	})
+}
@@ -1,7 +1,7 @@
 package examples
 
 import (
-	"encoding/json"
+	"net/http"
 
 	"github.com/zspkg/jac"
 )
@@ -12,56 +12,51 @@ type FooServiceConnector struct {
 	createFooEndpoint string
 }
 
-// Foo is the service object
+// Foo is the service object described with jsonapi tags
 type Foo struct {
-	bar int
-}
-
-// FooCreateResponse is the service response when creating Foo
-type FooCreateResponse struct {
-	id  int
-	bar int
+	ID  string `jsonapi:"primary,foo"`
+	Bar int    `jsonapi:"attr,bar"`
 }
 
 // NewFooServiceConnector is your custom connector to FooService where
 // you can define your own data transformations and operations and
 // then use jac.Jac's methods to easily send POST/GET/DELETE methods
-func NewFooServiceConnector(baseEndpoint string) *FooServiceConnector {
+func NewFooServiceConnector(baseEndpoint string, jwt string) *FooServiceConnector {
 	return &FooServiceConnector{
-		jac.NewJac(baseEndpoint),
+		jac.NewJac(baseEndpoint, jac.WithAuthenticator(jac.NewBearerAuth(jwt))),
 		"foo/create",
 	}
 }
 
 // CreateFoo is an example of simple connector function
 // which uses jac.Jac to create new Foo instance via connector
-func (c *FooServiceConnector) CreateFoo(foo Foo) (*FooCreateResponse, error) {
-	// rawing our Foo model
-	rawFoo, err := json.Marshal(foo)
-	if err != nil {
-		// your custom error handling
-	}
-
-	// creating response variable
-	var response FooCreateResponse
-
+func (c *FooServiceConnector) CreateFoo(foo Foo) (*Foo, error) {
 	// sending POST request to our service via connector
-	// to create new Foo instance
-	apiErrs, err := c.Post(
+	// to create new Foo instance. Payload is marshalled into
+	// JSON:API document, so no envelopes have to be built by hand
+	response, err := c.Do(
+		http.MethodPost,
 		jac.RequestParams{
 			Endpoint: c.createFooEndpoint,
-			Body:     rawFoo,
+			Payload:  &foo,
 		},
-		&response,
+		nil,
 	)
+	if jac.IsConflict(err) {
+		// API errors come back as *jac.APIError, so you can check their status
+	}
 	if err != nil {
 		// your custom error handling
+		return nil, err
 	}
-	if len(apiErrs) != 0 {
-		// you can handle API errs as you wish or just ignore them
+
+	// decoding created Foo from the JSON:API document in response
+	var created Foo
+	if _, err = response.DecodeResource(&created); err != nil {
+		// your custom error handling
+		return nil, err
 	}
 
-	// if err and apiErrs == nil, then you got a desired response from your service.
 	// Now you can simply return it
-	return &response, nil
+	return &created, nil
 }
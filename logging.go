@@ -0,0 +1,177 @@
+package jac
+
+import (
+	"net/http"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+// redactedValue replaces values of redacted headers in logs
+const redactedValue = "[REDACTED]"
+
+// LogSettings configures logging of outgoing calls. Zero levels are replaced
+// with the ones from DefaultLogSettings, and RedactHeaders are added to its ones
+type LogSettings struct {
+	// SuccessLevel is a level of calls that got 1xx, 2xx or 3xx responses
+	SuccessLevel logan.Level
+	// ClientErrorLevel is a level of calls that got 4xx responses
+	// or were cancelled by the caller
+	ClientErrorLevel logan.Level
+	// ServerErrorLevel is a level of calls that got 5xx responses
+	// or failed to get a response
+	ServerErrorLevel logan.Level
+	// SlowThreshold is a duration after which a call is logged at least
+	// at warn level. Zero disables it
+	SlowThreshold time.Duration
+	// Headers enables logging of request and response headers
+	Headers bool
+	// RedactHeaders are headers which values are never logged. Credentials
+	// and cookies redacted by default can not be unredacted
+	RedactHeaders []string
+}
+
+// DefaultLogSettings returns settings that log successful calls at debug,
+// client errors at info and server errors at error level with credentials
+// and cookies redacted
+func DefaultLogSettings() LogSettings {
+	return LogSettings{
+		SuccessLevel:     logan.DebugLevel,
+		ClientErrorLevel: logan.InfoLevel,
+		ServerErrorLevel: logan.ErrorLevel,
+		RedactHeaders: []string{
+			"Authorization",
+			"Proxy-Authorization",
+			"Cookie",
+			"Set-Cookie",
+			defaultAPIKeyHeader,
+		},
+	}
+}
+
+// withDefaults replaces zero levels of settings with default ones
+// and adds default redacted headers
+func (s LogSettings) withDefaults() LogSettings {
+	defaults := DefaultLogSettings()
+
+	if s.SuccessLevel == logan.PanicLevel {
+		s.SuccessLevel = defaults.SuccessLevel
+	}
+	if s.ClientErrorLevel == logan.PanicLevel {
+		s.ClientErrorLevel = defaults.ClientErrorLevel
+	}
+	if s.ServerErrorLevel == logan.PanicLevel {
+		s.ServerErrorLevel = defaults.ServerErrorLevel
+	}
+	s.RedactHeaders = append(defaults.RedactHeaders, s.RedactHeaders...)
+
+	return s
+}
+
+// LogMiddleware returns Middleware that logs every call with its method,
+// endpoint template, attempt, status, duration, byte counts and timing
+// breakdown if it is captured. Calls with a response are logged when its
+// body is closed, so duration and response bytes include reading the body
+func LogMiddleware(log *logan.Entry, settings LogSettings) Middleware {
+	settings = settings.withDefaults()
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			start := time.Now()
+
+			response, err := next.Do(call)
+			if err != nil {
+				settings.log(log, call, nil, err, time.Since(start), 0)
+				return nil, err
+			}
+
+			observeBody(response, func(read int64) {
+				settings.log(log, call, response, nil, time.Since(start), read)
+			})
+
+			return response, nil
+		})
+	}
+}
+
+// log logs a finished call at the level corresponding to its result
+func (s LogSettings) log(log *logan.Entry, call *Call, response *http.Response, err error, duration time.Duration, read int64) {
+	fields := logan.F{
+		"method":         call.Request.Method,
+		"endpoint":       call.Endpoint,
+		"attempt":        call.Attempt,
+		"duration":       duration,
+		"request_bytes":  contentLength(call.Request.ContentLength),
+		"response_bytes": read,
+	}
+	if id, ok := RequestIDFromContext(call.Request.Context()); ok {
+		fields["request_id"] = id
+	}
+	if span, ok := SpanFromContext(call.Request.Context()); ok {
+		fields["trace_id"], fields["span_id"] = span.TraceID, span.SpanID
+	}
+	if call.Timing != nil {
+		fields["dns"] = call.Timing.DNS
+		fields["connect"] = call.Timing.Connect
+		fields["tls_handshake"] = call.Timing.TLSHandshake
+		fields["time_to_first_byte"] = call.Timing.TimeToFirstByte
+		fields["body_read"] = call.Timing.BodyRead
+		fields["conn_reused"] = call.Timing.ConnReused
+	}
+	if s.Headers {
+		fields["request_headers"] = s.redact(call.Request.Header)
+	}
+
+	level, message := s.ServerErrorLevel, "outgoing call failed"
+	switch {
+	case err != nil:
+		class := classifyCallError(call, err)
+		fields["error_class"] = class
+		if class == ErrorClassCanceled {
+			level = s.ClientErrorLevel
+		}
+	case response.StatusCode >= http.StatusInternalServerError:
+		level, message = s.ServerErrorLevel, "outgoing call got server error"
+	case response.StatusCode >= http.StatusBadRequest:
+		level, message = s.ClientErrorLevel, "outgoing call got client error"
+	default:
+		level, message = s.SuccessLevel, "outgoing call"
+	}
+
+	if response != nil {
+		fields["status"] = response.StatusCode
+		if s.Headers {
+			fields["response_headers"] = s.redact(response.Header)
+		}
+	}
+
+	if s.SlowThreshold != 0 && duration >= s.SlowThreshold {
+		fields["slow"] = true
+		if level > logan.WarnLevel {
+			level = logan.WarnLevel
+		}
+	}
+
+	log.Log(uint32(level), fields, err, false, message)
+}
+
+// redact returns a copy of header with values of redacted headers replaced
+func (s LogSettings) redact(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, key := range s.RedactHeaders {
+		if _, ok := redacted[http.CanonicalHeaderKey(key)]; ok {
+			redacted[http.CanonicalHeaderKey(key)] = []string{redactedValue}
+		}
+	}
+
+	return redacted
+}
+
+// contentLength returns length of a request body or zero if it is unknown
+func contentLength(length int64) int64 {
+	if length < 0 {
+		return 0
+	}
+
+	return length
+}
@@ -0,0 +1,128 @@
+package jac
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+// newTestLog returns log writing JSON entries into buffer
+func newTestLog() (*logan.Entry, *bytes.Buffer) {
+	var buffer bytes.Buffer
+	return logan.New().Out(&buffer).Formatter(logan.JSONFormatter), &buffer
+}
+
+// logEntries decodes JSON entries written by a test log
+func logEntries(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var entry map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestJacer_Logging(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/users/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/users/slow":
+			time.Sleep(20 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"data":null}`))
+	}))
+	defer testServer.Close()
+
+	log, buffer := newTestLog()
+	testJac := NewJac(testServer.URL,
+		WithAuthenticator(NewBearerAuth("secret-token")),
+		WithLogger(log, LogSettings{Headers: true, SlowThreshold: 10 * time.Millisecond}),
+	)
+
+	for _, id := range []string{"1", "missing", "broken", "slow"} {
+		_, _ = testJac.Post(RequestParams{
+			Endpoint:   "users/{id}",
+			PathParams: map[string]string{"id": id},
+			Body:       []byte(`{}`),
+			Header:     map[string]string{"Authorization": "Bearer override", "X-Tenant": "acme"},
+		}, nil)
+	}
+
+	entries := logEntries(t, buffer)
+	assert.Len(t, entries, 4)
+
+	assert.Equal(t, "debug", entries[0]["level"])
+	assert.Equal(t, "outgoing call", entries[0]["msg"])
+	assert.Equal(t, "POST", entries[0]["method"])
+	assert.Equal(t, "users/{id}", entries[0]["endpoint"])
+	assert.Equal(t, float64(1), entries[0]["attempt"])
+	assert.Equal(t, float64(http.StatusOK), entries[0]["status"])
+	assert.Equal(t, float64(2), entries[0]["request_bytes"])
+	assert.Equal(t, float64(len(`{"data":null}`)), entries[0]["response_bytes"])
+	assert.Equal(t, map[string]interface{}{
+		"Authorization": []interface{}{redactedValue},
+		"X-Tenant":      []interface{}{"acme"},
+	}, filterHeaders(entries[0]["request_headers"], "Authorization", "X-Tenant"))
+
+	assert.Equal(t, "info", entries[1]["level"])
+	assert.Equal(t, float64(http.StatusNotFound), entries[1]["status"])
+
+	assert.Equal(t, "error", entries[2]["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), entries[2]["status"])
+
+	assert.Equal(t, "warning", entries[3]["level"])
+	assert.Equal(t, true, entries[3]["slow"])
+
+	t.Run("custom redacted headers extend default ones", func(t *testing.T) {
+		log, buffer := newTestLog()
+		_, _ = NewJac(testServer.URL, WithLogger(log, LogSettings{Headers: true, RedactHeaders: []string{"X-Secret"}})).
+			Get(RequestParams{Header: map[string]string{"Authorization": "Bearer token", "X-Secret": "secret", "X-Tenant": "acme"}}, nil)
+
+		entries := logEntries(t, buffer)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, map[string]interface{}{
+			"Authorization": []interface{}{redactedValue},
+			"X-Secret":      []interface{}{redactedValue},
+			"X-Tenant":      []interface{}{"acme"},
+		}, filterHeaders(entries[0]["request_headers"], "Authorization", "X-Secret", "X-Tenant"))
+	})
+	t.Run("transport error", func(t *testing.T) {
+		log, buffer := newTestLog()
+		_, err := NewJac("http://127.0.0.1:1", WithLogger(log, LogSettings{})).Get(RequestParams{}, nil)
+		assert.NotNil(t, err)
+
+		entries := logEntries(t, buffer)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "error", entries[0]["level"])
+		assert.Equal(t, "outgoing call failed", entries[0]["msg"])
+		assert.Equal(t, string(ErrorClassConnection), entries[0]["error_class"])
+	})
+}
+
+// filterHeaders returns only given keys of logged headers
+func filterHeaders(logged interface{}, keys ...string) map[string]interface{} {
+	headers, _ := logged.(map[string]interface{})
+
+	filtered := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		filtered[key] = headers[key]
+	}
+
+	return filtered
+}
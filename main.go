@@ -2,7 +2,11 @@ package jac
 
 import "github.com/google/jsonapi"
 
-// Jac is the interface that connector should implement
+// Jac is the interface that connector should implement.
+// Every method respects RequestParams.Context, so cancelling it
+// aborts the in-flight call with an error wrapping the context error.
+// If connector is created with WithAPIErrors, API error objects are
+// never returned, error responses come back as an *APIError instead.
 type Jac interface {
 	// Get sends GET request and reads response body into destination.
 	// Returns a slice of API error objects according to JSON API or
@@ -18,17 +22,56 @@ type Jac interface {
 	// Returns a slice of API error objects according to JSON API or
 	// error if some happened during the operation.
 	Patch(params RequestParams, destination any) ([]*jsonapi.ErrorObject, error)
+	// Put sends PUT request with provided data as a request body
+	// and reads response body if some data is expected to return.
+	// Returns a slice of API error objects according to JSON API or
+	// error if some happened during the operation.
+	Put(params RequestParams, destination any) ([]*jsonapi.ErrorObject, error)
 	// Delete sends DELETE request.
 	// Returns a slice of API error objects according to JSON API or
 	// error if some happened during the operation.
 	Delete(params RequestParams) ([]*jsonapi.ErrorObject, error)
-	// Exists checks if object exists by provided endpoint.
-	// Returns error if non-2xx status differs from 404 or
-	// something happened during the operation.
+	// Head sends HEAD request. Use Do to access response headers.
+	// Returns a slice of API error objects according to JSON API or
+	// error if some happened during the operation.
+	Head(params RequestParams) ([]*jsonapi.ErrorObject, error)
+	// Options sends OPTIONS request and reads response body
+	// if some data is expected to return.
+	// Returns a slice of API error objects according to JSON API or
+	// error if some happened during the operation.
+	Options(params RequestParams, destination any) ([]*jsonapi.ErrorObject, error)
+	// Request sends request with given method and reads response body
+	// if some data is expected to return.
+	// Returns a slice of API error objects according to JSON API or
+	// error if some happened during the operation.
+	Request(method string, params RequestParams, destination any) ([]*jsonapi.ErrorObject, error)
+	// Do sends request with given method and returns full response
+	// including its status code, headers and raw body. Body of a successful
+	// response is also decoded into destination if it is not nil.
+	// Returns *APIError together with the response if status code is
+	// equal or higher than 400 or error if some happened during the operation.
+	Do(method string, params RequestParams, destination any) (*Response, error)
+	// GetResource sends GET request and decodes JSON:API document with
+	// a single resource into model, which must be a pointer to a struct with
+	// jsonapi tags. Included relationships are resolved. Returns top-level
+	// links and meta of the document, *APIError if status code is equal
+	// or higher than 400 or error if some happened during the operation.
+	GetResource(params RequestParams, model any) (*Document, error)
+	// GetCollection sends GET request and decodes JSON:API document with
+	// a collection of resources into models, which must be a pointer to
+	// a slice of pointers to structs with jsonapi tags. Included relationships
+	// are resolved. Returns top-level links and meta of the document, *APIError
+	// if status code is equal or higher than 400 or error if some happened
+	// during the operation.
+	GetCollection(params RequestParams, models any) (*Document, error)
+	// Exists checks if object exists by provided endpoint using
+	// GET or HEAD request depending on configured ExistsStrategy.
+	// Returns *APIError if non-2xx status differs from 404 or
+	// error if something happened during the operation.
 	Exists(params RequestParams) (bool, error)
 	// NotExists checks if object is not exist by provided endpoint.
-	// Returns error if non-2xx status differs from 404 or
-	// something happened during the operation.
+	// Returns *APIError if non-2xx status differs from 404 or
+	// error if something happened during the operation.
 	NotExists(params RequestParams) (bool, error)
 }
 
@@ -43,5 +86,6 @@ type JACer interface {
 	// that can be found by specified key. It is useful when you need
 	// to configure multiple connectors to different services.
 	// If jacConfigKey is nil, default config key ``jac`` is used.
-	ConfigureJac(jacConfigKey *string) Jac
+	// Provided opts override the ones built from config.
+	ConfigureJac(jacConfigKey *string, opts ...Option) Jac
 }
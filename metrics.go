@@ -0,0 +1,372 @@
+package jac
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricsContentType is a content type of Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// defaultDurationBuckets are upper bounds of request duration histogram buckets in seconds
+var defaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects metrics of connectors and exposes them in Prometheus text
+// exposition format, so it can be mounted on a router as an http.Handler.
+// One Metrics can be shared by several connectors, which are told apart by
+// the connector label. Collected metrics are:
+//
+//   - jac_requests_total counter of attempts by connector, method, endpoint
+//     template, status class and error class
+//   - jac_request_duration_seconds histogram of attempts including reading
+//     of the response body by connector, method and endpoint template
+//   - jac_requests_in_flight gauge by connector
+//   - jac_retries_total counter of retried attempts by connector, method
+//     and endpoint template
+//   - jac_circuit_breaker_state gauge by connector and breaker, which is
+//     0 when closed, 1 when open and 2 when half-open. A breaker is reported
+//     since its first state change
+//
+// Connectors with timing enabled by WithTiming also record:
+//
+//   - jac_request_phase_duration_seconds histogram of dns, connect,
+//     tls_handshake, time_to_first_byte and body_read phases of attempts
+//     by connector and phase. Phases that did not happen are not observed
+//   - jac_connections_total counter of connections used by attempts
+//     by connector and whether they were reused
+type Metrics struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	requests  map[requestSeries]uint64
+	durations map[endpointSeries]*histogram
+	inFlight  map[string]int64
+	retries   map[endpointSeries]uint64
+	breakers  map[breakerSeries]BreakerState
+
+	phases      map[phaseSeries]*histogram
+	connections map[connectionSeries]uint64
+}
+
+// requestSeries are labels of jac_requests_total
+type requestSeries struct {
+	connector, method, endpoint, statusClass, errorClass string
+}
+
+// endpointSeries are labels of per endpoint metrics
+type endpointSeries struct {
+	connector, method, endpoint string
+}
+
+// breakerSeries are labels of jac_circuit_breaker_state
+type breakerSeries struct {
+	connector, breaker string
+}
+
+// phaseSeries are labels of jac_request_phase_duration_seconds
+type phaseSeries struct {
+	connector, phase string
+}
+
+// connectionSeries are labels of jac_connections_total
+type connectionSeries struct {
+	connector string
+	reused    bool
+}
+
+// histogram contains cumulative counts of observations per bucket
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics returns empty Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets:   defaultDurationBuckets,
+		requests:  make(map[requestSeries]uint64),
+		durations: make(map[endpointSeries]*histogram),
+		inFlight:  make(map[string]int64),
+		retries:   make(map[endpointSeries]uint64),
+		breakers:  make(map[breakerSeries]BreakerState),
+
+		phases:      make(map[phaseSeries]*histogram),
+		connections: make(map[connectionSeries]uint64),
+	}
+}
+
+// Middleware returns Middleware that records every call into metrics with
+// the connector label. Attempts after the first one are counted as retries
+func (m *Metrics) Middleware(connector string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			endpoint := endpointSeries{connector: connector, method: call.Request.Method, endpoint: call.Endpoint}
+			m.started(endpoint, call.Attempt)
+			start := time.Now()
+
+			response, err := next.Do(call)
+			if err != nil {
+				m.finished(endpoint, "", classifyCallError(call, err), time.Since(start))
+				m.timed(connector, call.Timing)
+				return nil, err
+			}
+
+			observeBody(response, func(int64) {
+				m.finished(endpoint, statusClass(response.StatusCode), "", time.Since(start))
+				m.timed(connector, call.Timing)
+			})
+
+			return response, nil
+		})
+	}
+}
+
+// started records the start of an attempt
+func (m *Metrics) started(endpoint endpointSeries, attempt int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[endpoint.connector]++
+	if attempt > 1 {
+		m.retries[endpoint]++
+	}
+}
+
+// finished records the result of an attempt
+func (m *Metrics) finished(endpoint endpointSeries, status string, class ErrorClass, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[endpoint.connector]--
+
+	m.requests[requestSeries{
+		connector:   endpoint.connector,
+		method:      endpoint.method,
+		endpoint:    endpoint.endpoint,
+		statusClass: status,
+		errorClass:  string(class),
+	}]++
+
+	durations, ok := m.durations[endpoint]
+	if !ok {
+		durations = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[endpoint] = durations
+	}
+	durations.observe(m.buckets, duration)
+}
+
+// timed records timing breakdown of an attempt unless it is nil
+func (m *Metrics) timed(connector string, timing *Timing) {
+	if timing == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	phases := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"dns", timing.DNS},
+		{"connect", timing.Connect},
+		{"tls_handshake", timing.TLSHandshake},
+		{"time_to_first_byte", timing.TimeToFirstByte},
+		{"body_read", timing.BodyRead},
+	}
+	for _, phase := range phases {
+		if phase.duration == 0 {
+			continue
+		}
+
+		series := phaseSeries{connector: connector, phase: phase.name}
+		durations, ok := m.phases[series]
+		if !ok {
+			durations = &histogram{counts: make([]uint64, len(m.buckets))}
+			m.phases[series] = durations
+		}
+		durations.observe(m.buckets, phase.duration)
+	}
+
+	// a connection is got only if time to first byte is known
+	if timing.TimeToFirstByte != 0 {
+		m.connections[connectionSeries{connector: connector, reused: timing.ConnReused}]++
+	}
+}
+
+// breakerStateChanged records a new state of connector breaker
+func (m *Metrics) breakerStateChanged(connector, breaker string, state BreakerState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.breakers[breakerSeries{connector: connector, breaker: breaker}] = state
+}
+
+// ServeHTTP writes metrics in Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	_, _ = io.WriteString(w, m.expose())
+}
+
+// expose returns metrics in Prometheus text exposition format
+func (m *Metrics) expose() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var out strings.Builder
+
+	writeHeader(&out, "jac_requests_total", "counter", "Total number of request attempts sent by connectors.")
+	var lines []string
+	for series, value := range m.requests {
+		lines = append(lines, sample("jac_requests_total", labels(
+			"connector", series.connector,
+			"method", series.method,
+			"endpoint", series.endpoint,
+			"status_class", series.statusClass,
+			"error_class", series.errorClass,
+		), strconv.FormatUint(value, 10)))
+	}
+	writeLines(&out, lines)
+
+	writeHeader(&out, "jac_request_duration_seconds", "histogram", "Duration of request attempts including reading of the response body.")
+	lines = nil
+	for series, durations := range m.durations {
+		lines = append(lines, durations.samples("jac_request_duration_seconds", m.buckets,
+			"connector", series.connector,
+			"method", series.method,
+			"endpoint", series.endpoint,
+		))
+	}
+	writeLines(&out, lines)
+
+	writeHeader(&out, "jac_requests_in_flight", "gauge", "Number of request attempts in flight.")
+	lines = nil
+	for connector, value := range m.inFlight {
+		lines = append(lines, sample("jac_requests_in_flight", labels("connector", connector), strconv.FormatInt(value, 10)))
+	}
+	writeLines(&out, lines)
+
+	writeHeader(&out, "jac_retries_total", "counter", "Total number of retried request attempts.")
+	lines = nil
+	for series, value := range m.retries {
+		lines = append(lines, sample("jac_retries_total", labels(
+			"connector", series.connector,
+			"method", series.method,
+			"endpoint", series.endpoint,
+		), strconv.FormatUint(value, 10)))
+	}
+	writeLines(&out, lines)
+
+	writeHeader(&out, "jac_circuit_breaker_state", "gauge", "State of circuit breakers: 0 is closed, 1 is open, 2 is half-open.")
+	lines = nil
+	for series, state := range m.breakers {
+		lines = append(lines, sample("jac_circuit_breaker_state", labels(
+			"connector", series.connector,
+			"breaker", series.breaker,
+		), strconv.Itoa(int(state))))
+	}
+	writeLines(&out, lines)
+
+	if len(m.phases) != 0 || len(m.connections) != 0 {
+		writeHeader(&out, "jac_request_phase_duration_seconds", "histogram", "Duration of phases of request attempts.")
+		lines = nil
+		for series, durations := range m.phases {
+			lines = append(lines, durations.samples("jac_request_phase_duration_seconds", m.buckets,
+				"connector", series.connector,
+				"phase", series.phase,
+			))
+		}
+		writeLines(&out, lines)
+
+		writeHeader(&out, "jac_connections_total", "counter", "Total number of connections used by request attempts.")
+		lines = nil
+		for series, value := range m.connections {
+			lines = append(lines, sample("jac_connections_total", labels(
+				"connector", series.connector,
+				"reused", strconv.FormatBool(series.reused),
+			), strconv.FormatUint(value, 10)))
+		}
+		writeLines(&out, lines)
+	}
+
+	return out.String()
+}
+
+// observe records duration into the histogram with given bucket bounds
+func (h *histogram) observe(buckets []float64, duration time.Duration) {
+	seconds := duration.Seconds()
+	for i, bound := range buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// samples returns histogram samples, one per line
+func (h *histogram) samples(name string, buckets []float64, labelPairs ...string) string {
+	lines := make([]string, 0, len(buckets)+3)
+	bucket := func(le string) string {
+		return labels(append(append([]string(nil), labelPairs...), "le", le)...)
+	}
+
+	for i, bound := range buckets {
+		lines = append(lines, sample(name+"_bucket", bucket(strconv.FormatFloat(bound, 'g', -1, 64)), strconv.FormatUint(h.counts[i], 10)))
+	}
+	lines = append(lines,
+		sample(name+"_bucket", bucket("+Inf"), strconv.FormatUint(h.count, 10)),
+		sample(name+"_sum", labels(labelPairs...), strconv.FormatFloat(h.sum, 'g', -1, 64)),
+		sample(name+"_count", labels(labelPairs...), strconv.FormatUint(h.count, 10)),
+	)
+
+	return strings.Join(lines, "\n")
+}
+
+// writeHeader writes HELP and TYPE lines of a metric
+func writeHeader(out *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// writeLines writes samples sorted, so the output is stable
+func writeLines(out *strings.Builder, lines []string) {
+	sort.Strings(lines)
+	for _, line := range lines {
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+}
+
+// sample formats a single sample line
+func sample(name, labels, value string) string {
+	return name + labels + " " + value
+}
+
+// labels formats label pairs given as name, value, name, value...
+func labels(pairs ...string) string {
+	formatted := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		formatted = append(formatted, pairs[i]+`="`+escapeLabelValue(pairs[i+1])+`"`)
+	}
+
+	return "{" + strings.Join(formatted, ",") + "}"
+}
+
+// labelValueReplacer escapes label values according to the text exposition format
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+// statusClass returns a class of status code like "2xx"
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
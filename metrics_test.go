@@ -0,0 +1,58 @@
+package jac
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJacer_Metrics(t *testing.T) {
+	server, _ := newFlakyServer(1, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	brokenServer, _ := newFlakyServer(1, http.StatusInternalServerError, nil)
+	defer brokenServer.Close()
+
+	metrics := NewMetrics()
+	testJac := NewJac(server.URL, WithMetrics(metrics, "users"), WithRetryPolicy(testRetryPolicy))
+
+	_, err := testJac.Get(RequestParams{Endpoint: "users/{id}", PathParams: map[string]string{"id": "1"}}, nil)
+	assert.Nil(t, err)
+
+	_, err = NewJac(brokenServer.URL,
+		WithMetrics(metrics, "orders"),
+		WithCircuitBreaker(BreakerSettings{MinRequests: 1, FailureRatio: 1, OpenDuration: time.Minute}),
+	).Get(RequestParams{Endpoint: "orders"}, nil)
+	assert.Nil(t, err)
+
+	_, err = NewJac("http://127.0.0.1:1", WithMetrics(metrics, `say "hi"`)).Get(RequestParams{Endpoint: "users"}, nil)
+	assert.NotNil(t, err)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, metricsContentType, recorder.Header().Get("Content-Type"))
+
+	body, _ := io.ReadAll(recorder.Body)
+	exposition := string(body)
+
+	for _, line := range []string{
+		"# TYPE jac_requests_total counter",
+		`jac_requests_total{connector="users",method="GET",endpoint="users/{id}",status_class="5xx",error_class=""} 1`,
+		`jac_requests_total{connector="users",method="GET",endpoint="users/{id}",status_class="2xx",error_class=""} 1`,
+		`jac_requests_total{connector="say \"hi\"",method="GET",endpoint="users",status_class="",error_class="connection"} 1`,
+		"# TYPE jac_request_duration_seconds histogram",
+		`jac_request_duration_seconds_bucket{connector="users",method="GET",endpoint="users/{id}",le="+Inf"} 2`,
+		`jac_request_duration_seconds_count{connector="users",method="GET",endpoint="users/{id}"} 2`,
+		`jac_requests_in_flight{connector="users"} 0`,
+		`jac_retries_total{connector="users",method="GET",endpoint="users/{id}"} 1`,
+		`jac_requests_total{connector="orders",method="GET",endpoint="orders",status_class="5xx",error_class=""} 1`,
+		`jac_circuit_breaker_state{connector="orders",breaker=""} 1`,
+	} {
+		assert.Contains(t, strings.Split(exposition, "\n"), line)
+	}
+}
@@ -0,0 +1,124 @@
+package jac
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Call describes a single request sent by a connector
+type Call struct {
+	// Request is the request to send. Its body can be read again with GetBody
+	Request *http.Request
+	// Endpoint is RequestParams.Endpoint, which is a low-cardinality
+	// label of the request when it is a template
+	Endpoint string
+	// Attempt is a number of the attempt starting from 1.
+	// It is set by RetryMiddleware for the middlewares it wraps
+	Attempt int
+	// Timing is a breakdown of the attempt, which is nil unless timing is
+	// enabled with WithTiming. It is filled when the response is received
+	// and completed when its body is closed
+	Timing *Timing
+
+	// header contains headers set per request, so they can be
+	// restored after authentication
+	header http.Header
+	// attempts is shared by copies of the call, so the connector
+	// learns how many attempts RetryMiddleware made
+	attempts *int
+}
+
+// Clone returns a copy of the call with a deep copy of the request
+// which body is read from the start
+func (c *Call) Clone() (*Call, error) {
+	request := c.Request.Clone(c.Request.Context())
+	if c.Request.GetBody != nil {
+		body, err := c.Request.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get request body")
+		}
+		request.Body = body
+	}
+
+	clone := *c
+	clone.Request = request
+
+	return &clone, nil
+}
+
+// Doer sends a call and returns its response
+type Doer interface {
+	// Do sends call. Response body must be closed by the caller
+	Do(call *Call) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as Doer
+type DoerFunc func(call *Call) (*http.Response, error)
+
+// Do calls f(call)
+func (f DoerFunc) Do(call *Call) (*http.Response, error) {
+	return f(call)
+}
+
+// Middleware wraps a Doer to add logic around sending calls, for example:
+//
+//	func(next jac.Doer) jac.Doer {
+//		return jac.DoerFunc(func(call *jac.Call) (*http.Response, error) {
+//			call.Request.Header.Set("X-Signature", sign(call.Request))
+//			return next.Do(call)
+//		})
+//	}
+type Middleware func(next Doer) Doer
+
+// chain wraps doer with middlewares, so the first one is the outermost
+func chain(doer Doer, middlewares ...Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		doer = middlewares[i](doer)
+	}
+
+	return doer
+}
+
+// clientDoer returns Doer that sends calls with client.
+// Timing of calls is captured if they have it
+func clientDoer(client *http.Client) Doer {
+	return DoerFunc(func(call *Call) (*http.Response, error) {
+		if call.Timing != nil {
+			return timedDo(client, call)
+		}
+
+		return client.Do(call.Request)
+	})
+}
+
+// observeBody wraps response body, so onClose is called once with
+// a number of bytes read when the body is closed
+func observeBody(response *http.Response, onClose func(read int64)) {
+	response.Body = &observedBody{ReadCloser: response.Body, onClose: onClose}
+}
+
+// observedBody counts bytes read from the body and reports them on close
+type observedBody struct {
+	io.ReadCloser
+	read    int64
+	once    sync.Once
+	onClose func(read int64)
+}
+
+func (b *observedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+func (b *observedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.onClose(b.read)
+	})
+
+	return err
+}
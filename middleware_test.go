@@ -0,0 +1,69 @@
+package jac
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingMiddleware returns middleware that records name, endpoint
+// and attempt of every call it sees into records
+func recordingMiddleware(name string, records *[]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			*records = append(*records, fmt.Sprintf("%s %s %d", name, call.Endpoint, call.Attempt))
+			return next.Do(call)
+		})
+	}
+}
+
+func TestJacer_Middleware(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`"` + r.Header.Get("X-Signature") + " " + r.Header.Get("Authorization") + `"`))
+	}))
+	defer testServer.Close()
+
+	var records []string
+	signer := func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			call.Request.Header.Set("X-Signature", call.Request.Method+" "+call.Request.URL.Path)
+			return next.Do(call)
+		})
+	}
+
+	testJac := NewJac(testServer.URL,
+		WithAuthenticator(NewBearerAuth("token")),
+		WithMiddleware(recordingMiddleware("first", &records), signer),
+		WithMiddleware(recordingMiddleware("second", &records)),
+	)
+
+	var result string
+	_, err := testJac.Get(RequestParams{Endpoint: "users/{id}", PathParams: map[string]string{"id": "1"}}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, "GET /users/1 Bearer token", result)
+	assert.Equal(t, []string{"first users/{id} 1", "second users/{id} 1"}, records)
+}
+
+func TestJacer_MiddlewareAttempts(t *testing.T) {
+	server, hits := newFlakyServer(2, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	var outer, inner []string
+	testJac := NewJac(server.URL, WithMiddleware(
+		recordingMiddleware("outer", &outer),
+		RetryMiddleware(testRetryPolicy),
+		recordingMiddleware("inner", &inner),
+	))
+
+	var result map[string]string
+	_, err := testJac.Request(http.MethodGet, RequestParams{Endpoint: "users", Body: []byte(`{"name":"john"}`)}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"name": "john"}, result)
+	assert.Equal(t, int64(3), *hits)
+
+	assert.Equal(t, []string{"outer users 1"}, outer)
+	assert.Equal(t, []string{"inner users 1", "inner users 2", "inner users 3"}, inner)
+}
@@ -0,0 +1,207 @@
+package jac
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AuthTypeOAuth2 selects OAuth2 client credentials authentication
+	AuthTypeOAuth2 = "oauth2"
+
+	// oauth2ExpiryDelta is how long before the expiration token is considered expired
+	oauth2ExpiryDelta = 10 * time.Second
+	// defaultOAuth2Timeout is a time limit for a token request if none is configured
+	defaultOAuth2Timeout = 30 * time.Second
+)
+
+// Refresher is implemented by authenticators whose credentials can be
+// refreshed after the server rejected them with 401 Unauthorized.
+// Connector calls Refresh with the rejected request and retries it once.
+type Refresher interface {
+	// Refresh drops credentials used by the rejected request r,
+	// so that the next Authenticate call gets fresh ones
+	Refresh(r *http.Request) error
+}
+
+// OAuth2Config contains data needed to obtain a token
+// with OAuth2 client credentials grant
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient is used to request tokens. If nil, http.DefaultClient is used
+	HTTPClient *http.Client
+	// Timeout is a time limit for a token request, 30 seconds by default.
+	// Token request is shared by concurrent callers, so it is not bound
+	// to the context of any of them
+	Timeout time.Duration
+}
+
+// oauth2Token is a token endpoint response
+type oauth2Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// oauth2Call is an in-flight token request shared by concurrent callers
+type oauth2Call struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// oauth2Auth is an Authenticator that uses OAuth2 client credentials grant
+type oauth2Auth struct {
+	config OAuth2Config
+
+	mu      sync.Mutex
+	token   string
+	expiry  time.Time
+	pending *oauth2Call
+}
+
+// NewOAuth2Auth returns Authenticator that obtains bearer tokens with OAuth2
+// client credentials grant. Tokens are cached until shortly before they expire,
+// concurrent token requests are collapsed into one. It implements Refresher,
+// so a request rejected with 401 Unauthorized is retried once with a new token
+func NewOAuth2Auth(config OAuth2Config) Authenticator {
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultOAuth2Timeout
+	}
+
+	return &oauth2Auth{config: config}
+}
+
+func (a *oauth2Auth) Authenticate(r *http.Request) error {
+	token, err := a.getToken(r.Context())
+	if err != nil {
+		return errors.Wrap(err, "failed to get oauth2 token")
+	}
+
+	setBearer(r, token)
+	return nil
+}
+
+func (a *oauth2Auth) Refresh(r *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// token could have already been refreshed by a concurrent request
+	if r.Header.Get("Authorization") == "Bearer "+a.token {
+		a.token = ""
+	}
+
+	return nil
+}
+
+// getToken returns cached token or requests a new one if it is expired.
+// Only one token request is in flight at a time, and every caller waits
+// for it until its own ctx is done
+func (a *oauth2Auth) getToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	if a.token != "" && (a.expiry.IsZero() || time.Now().Before(a.expiry)) {
+		defer a.mu.Unlock()
+		return a.token, nil
+	}
+
+	call := a.pending
+	if call == nil {
+		call = &oauth2Call{done: make(chan struct{})}
+		a.pending = call
+		go a.fetch(call)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// fetch requests a new token and stores result in call and cache.
+// The request is detached from callers, so none of them can cancel it for the others
+func (a *oauth2Auth) fetch(call *oauth2Call) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Timeout)
+	defer cancel()
+
+	token, err := a.requestToken(ctx)
+
+	a.mu.Lock()
+	a.pending = nil
+	if err == nil {
+		a.token = token.AccessToken
+		a.expiry = time.Time{}
+		if token.ExpiresIn > 0 {
+			a.expiry = time.Now().Add(tokenLifetime(time.Duration(token.ExpiresIn) * time.Second))
+		}
+	}
+	a.mu.Unlock()
+
+	call.token, call.err = token.AccessToken, err
+	close(call.done)
+}
+
+// requestToken sends client credentials grant request to the token endpoint
+func (a *oauth2Auth) requestToken(ctx context.Context) (oauth2Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.config.Scopes) != 0 {
+		form.Set("scope", strings.Join(a.config.Scopes, " "))
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return oauth2Token{}, errors.Wrap(err, "failed to create token request")
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(url.QueryEscape(a.config.ClientID), url.QueryEscape(a.config.ClientSecret))
+
+	response, err := a.config.HTTPClient.Do(request)
+	if err != nil {
+		return oauth2Token{}, errors.Wrap(err, "failed to send token request")
+	}
+	defer response.Body.Close()
+
+	raw, err := io.ReadAll(response.Body)
+	if err != nil {
+		return oauth2Token{}, errors.Wrap(err, "failed to read token response")
+	}
+	if response.StatusCode != http.StatusOK {
+		return oauth2Token{}, errors.Errorf("token endpoint responded with status %d: %s", response.StatusCode, raw)
+	}
+
+	var token oauth2Token
+	if err = json.Unmarshal(raw, &token); err != nil {
+		return oauth2Token{}, errors.Wrap(err, "failed to unmarshal token response")
+	}
+	if token.AccessToken == "" {
+		return oauth2Token{}, errors.New("token endpoint responded with empty access token")
+	}
+
+	return token, nil
+}
+
+// tokenLifetime returns how long a token that expires in given time can be used
+func tokenLifetime(lifetime time.Duration) time.Duration {
+	delta := oauth2ExpiryDelta
+	if delta > lifetime/2 {
+		delta = lifetime / 2
+	}
+
+	return lifetime - delta
+}
@@ -0,0 +1,162 @@
+package jac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testOAuth2Server issues sequential tokens and serves an API that
+// accepts only the latest issued one
+type testOAuth2Server struct {
+	issued  int64
+	revoked int64
+	apiHits int64
+}
+
+func (s *testOAuth2Server) tokenHandler(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != "my-client" || clientSecret != "my-secret" ||
+		r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "read write" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// slowing down token issuing so that concurrent requests overlap
+	time.Sleep(20 * time.Millisecond)
+	token := atomic.AddInt64(&s.issued, 1)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": fmt.Sprintf("token-%d", token),
+		"token_type":   "bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (s *testOAuth2Server) apiHandler(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&s.apiHits, 1)
+	issued := atomic.LoadInt64(&s.issued)
+	if issued == atomic.LoadInt64(&s.revoked) || r.Header.Get("Authorization") != fmt.Sprintf("Bearer token-%d", issued) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"errors":[{"status":"401"}]}`))
+		return
+	}
+
+	_, _ = w.Write([]byte(`"ok"`))
+}
+
+func TestOAuth2Auth(t *testing.T) {
+	oauth2Server := &testOAuth2Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", oauth2Server.tokenHandler)
+	mux.HandleFunc("/api", oauth2Server.apiHandler)
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	testJac := NewJac(testServer.URL, WithAuthenticator(NewOAuth2Auth(OAuth2Config{
+		TokenURL:     testServer.URL + "/token",
+		ClientID:     "my-client",
+		ClientSecret: "my-secret",
+		Scopes:       []string{"read", "write"},
+	})))
+
+	get := func() (string, error) {
+		var response string
+		apiErrs, err := testJac.Get(RequestParams{Endpoint: "api"}, &response)
+		if len(apiErrs) != 0 {
+			return "", fmt.Errorf("unexpected api error with status %s", apiErrs[0].Status)
+		}
+
+		return response, err
+	}
+
+	t.Run("token is cached", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			response, err := get()
+			assert.Nil(t, err)
+			assert.Equal(t, "ok", response)
+		}
+		assert.Equal(t, int64(1), atomic.LoadInt64(&oauth2Server.issued))
+	})
+	t.Run("token is refreshed on 401", func(t *testing.T) {
+		atomic.StoreInt64(&oauth2Server.revoked, atomic.LoadInt64(&oauth2Server.issued))
+		atomic.StoreInt64(&oauth2Server.apiHits, 0)
+
+		response, err := get()
+		assert.Nil(t, err)
+		assert.Equal(t, "ok", response)
+		assert.Equal(t, int64(2), atomic.LoadInt64(&oauth2Server.issued))
+		assert.Equal(t, int64(2), atomic.LoadInt64(&oauth2Server.apiHits), "expected request to be retried once")
+	})
+	t.Run("concurrent refreshes are collapsed", func(t *testing.T) {
+		atomic.StoreInt64(&oauth2Server.revoked, atomic.LoadInt64(&oauth2Server.issued))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				response, err := get()
+				assert.Nil(t, err)
+				assert.Equal(t, "ok", response)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(3), atomic.LoadInt64(&oauth2Server.issued))
+	})
+}
+
+func TestOAuth2Auth_CancelledCaller(t *testing.T) {
+	oauth2Server := &testOAuth2Server{}
+	testServer := httptest.NewServer(http.HandlerFunc(oauth2Server.tokenHandler))
+	defer testServer.Close()
+
+	auth := NewOAuth2Auth(OAuth2Config{
+		TokenURL:     testServer.URL,
+		ClientID:     "my-client",
+		ClientSecret: "my-secret",
+		Scopes:       []string{"read", "write"},
+	})
+
+	// the first caller starts the token request and gives up before it is done
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	leader, _ := http.NewRequestWithContext(ctx, http.MethodGet, testServer.URL, nil)
+	follower, _ := http.NewRequest(http.MethodGet, testServer.URL, nil)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NotNil(t, auth.Authenticate(leader))
+	}()
+	time.Sleep(time.Millisecond)
+
+	assert.Nil(t, auth.Authenticate(follower))
+	assert.Equal(t, "Bearer token-1", follower.Header.Get("Authorization"))
+	wg.Wait()
+	assert.Equal(t, int64(1), atomic.LoadInt64(&oauth2Server.issued))
+}
+
+func TestOAuth2Auth_InvalidCredentials(t *testing.T) {
+	oauth2Server := &testOAuth2Server{}
+	testServer := httptest.NewServer(http.HandlerFunc(oauth2Server.tokenHandler))
+	defer testServer.Close()
+
+	testJac := NewJac(testServer.URL, WithAuthenticator(NewOAuth2Auth(OAuth2Config{
+		TokenURL:     testServer.URL,
+		ClientID:     "my-client",
+		ClientSecret: "wrong-secret",
+	})))
+
+	_, err := testJac.Get(RequestParams{}, nil)
+	assert.NotNil(t, err)
+}
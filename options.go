@@ -0,0 +1,314 @@
+package jac
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+// Version is a version of the package sent in the default User-Agent
+const Version = "1.0.0"
+
+// ExistsStrategy is a way Exists checks if an object exists
+type ExistsStrategy string
+
+const (
+	// ExistsByGet sends GET request and discards the response body. It is the default strategy
+	ExistsByGet ExistsStrategy = "get"
+	// ExistsByHead sends HEAD request, so no response body is transferred
+	ExistsByHead ExistsStrategy = "head"
+)
+
+// Option configures a connector created by NewJac
+type Option func(*options)
+
+// options contains all values that can be configured via Option
+type options struct {
+	client    *http.Client
+	timeout   *time.Duration
+	transport http.RoundTripper
+	auth      Authenticator
+	retry     *RetryPolicy
+	breaker   *BreakerSettings
+	apiErrors bool
+
+	existsStrategy ExistsStrategy
+
+	header      http.Header
+	userAgent   string
+	serviceName string
+
+	middlewares []Middleware
+
+	log         *logan.Entry
+	logSettings LogSettings
+
+	metrics          *Metrics
+	metricsConnector string
+
+	tracing      bool
+	spanRecorder SpanRecorder
+
+	requestIDHeader string
+
+	timing bool
+}
+
+// WithHTTPClient sets a client that is used to send requests.
+// The client is copied, so WithTimeout and WithTransport never modify it.
+// Timeout and transport set by earlier options, e.g. the ones built from
+// config, are dropped, so the client is used as is unless they are set again.
+// By default, or if client is nil, http.DefaultClient is used
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		if client == nil {
+			client = http.DefaultClient
+		}
+		o.client = client
+		o.timeout = nil
+		o.transport = nil
+	}
+}
+
+// WithTimeout sets a time limit for requests made by the connector
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = &timeout
+	}
+}
+
+// WithTransport sets a transport that is used to send requests
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithAuthenticator sets an Authenticator that is called on every request
+func WithAuthenticator(auth Authenticator) Option {
+	return func(o *options) {
+		o.auth = auth
+	}
+}
+
+// WithRetryPolicy enables retries of failed requests according to policy.
+// By default, every request is sent only once
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		policy = policy.withDefaults()
+		o.retry = &policy
+	}
+}
+
+// WithCircuitBreaker enables circuit breaker configured with settings.
+// Open breaker fails requests fast with ErrCircuitOpen
+func WithCircuitBreaker(settings BreakerSettings) Option {
+	return func(o *options) {
+		settings = settings.withDefaults()
+		o.breaker = &settings
+	}
+}
+
+// WithAPIErrors makes connector return API errors as an *APIError
+// error instead of a slice of error objects
+func WithAPIErrors() Option {
+	return func(o *options) {
+		o.apiErrors = true
+	}
+}
+
+// WithExistsStrategy sets a strategy Exists and NotExists use to check objects
+func WithExistsStrategy(strategy ExistsStrategy) Option {
+	return func(o *options) {
+		o.existsStrategy = strategy
+	}
+}
+
+// WithHeaders adds headers that are sent with every request. Headers set by
+// the authenticator and per request override them, key by key
+func WithHeaders(header http.Header) Option {
+	return func(o *options) {
+		if o.header == nil {
+			o.header = make(http.Header, len(header))
+		}
+		setHeader(o.header, header)
+	}
+}
+
+// WithUserAgent sets User-Agent header sent with every request.
+// By default, it is "jac/<version> (<service name>)"
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithServiceName sets a service name sent in the default User-Agent.
+// By default, it is the name of the executable
+func WithServiceName(name string) Option {
+	return func(o *options) {
+		o.serviceName = name
+	}
+}
+
+// WithLogger enables logging of every attempt of outgoing calls into log.
+// Calls rejected by an open circuit breaker are logged too
+func WithLogger(log *logan.Entry, settings LogSettings) Option {
+	return func(o *options) {
+		o.log = log
+		o.logSettings = settings
+	}
+}
+
+// WithMetrics enables recording of every attempt of outgoing calls and
+// circuit breaker states into metrics labelled with connector name
+func WithMetrics(metrics *Metrics, connector string) Option {
+	return func(o *options) {
+		o.metrics = metrics
+		o.metricsConnector = connector
+	}
+}
+
+// WithTracing enables W3C trace context propagation. Every attempt of
+// outgoing calls is a child span of the span from the request context and
+// is reported to recorder. Recorder can be nil to only propagate traces
+func WithTracing(recorder SpanRecorder) Option {
+	return func(o *options) {
+		o.tracing = true
+		o.spanRecorder = recorder
+	}
+}
+
+// WithRequestIDHeader sets a header request id is sent in, X-Request-ID
+// by default. Empty header disables sending of request ids
+func WithRequestIDHeader(header string) Option {
+	return func(o *options) {
+		o.requestIDHeader = header
+	}
+}
+
+// WithTiming enables capturing of DNS lookup, connect, TLS handshake, time
+// to first byte and body read durations of every attempt. They are available
+// in Call.Timing to middlewares, in Response.Timing for the last attempt,
+// and are logged and recorded into metrics if those are enabled
+func WithTiming() Option {
+	return func(o *options) {
+		o.timing = true
+	}
+}
+
+// WithMiddleware adds middlewares to the connector chain. The first one is
+// the outermost, and all of them wrap retries, tracing, logging, metrics,
+// circuit breaker and authentication, so they see a call once however many
+// attempts it takes. Use RetryMiddleware, CircuitBreakerMiddleware and AuthMiddleware instead
+// of the corresponding options to put them elsewhere in the chain
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// newOptions applies given opts on top of the default options
+func newOptions(opts ...Option) options {
+	o := options{
+		client:         http.DefaultClient,
+		existsStrategy: ExistsByGet,
+		serviceName:    filepath.Base(os.Args[0]),
+
+		requestIDHeader: defaultRequestIDHeader,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// httpClient returns a copy of configured client with timeout and transport applied
+func (o options) httpClient() *http.Client {
+	client := *o.client
+
+	if o.timeout != nil {
+		client.Timeout = *o.timeout
+	}
+	if o.transport != nil {
+		client.Transport = o.transport
+	}
+
+	return &client
+}
+
+// defaultHeader returns headers sent with every request including User-Agent.
+// User-Agent set with WithHeaders takes precedence over WithUserAgent
+func (o options) defaultHeader() http.Header {
+	header := o.header.Clone()
+	if header == nil {
+		header = make(http.Header, 1)
+	}
+
+	if header.Get("User-Agent") != "" {
+		return header
+	}
+
+	switch {
+	case o.userAgent != "":
+		header.Set("User-Agent", o.userAgent)
+	case o.serviceName != "":
+		header.Set("User-Agent", fmt.Sprintf("jac/%s (%s)", Version, o.serviceName))
+	default:
+		header.Set("User-Agent", "jac/"+Version)
+	}
+
+	return header
+}
+
+// doer returns Doer that sends calls with the configured client through
+// middlewares followed by retries, tracing, logging, metrics, circuit breaker
+// and authentication
+func (o options) doer() Doer {
+	middlewares := append([]Middleware(nil), o.middlewares...)
+
+	if o.retry != nil {
+		middlewares = append(middlewares, RetryMiddleware(*o.retry))
+	}
+	if o.tracing {
+		middlewares = append(middlewares, TracingMiddleware(o.spanRecorder))
+	}
+	if o.log != nil {
+		middlewares = append(middlewares, LogMiddleware(o.log, o.logSettings))
+	}
+	if o.metrics != nil {
+		middlewares = append(middlewares, o.metrics.Middleware(o.metricsConnector))
+	}
+	if o.breaker != nil {
+		middlewares = append(middlewares, CircuitBreakerMiddleware(o.breakerSettings()))
+	}
+	if o.auth != nil {
+		middlewares = append(middlewares, AuthMiddleware(o.auth))
+	}
+
+	return chain(clientDoer(o.httpClient()), middlewares...)
+}
+
+// breakerSettings returns circuit breaker settings that
+// also report state changes to metrics if they are enabled
+func (o options) breakerSettings() BreakerSettings {
+	settings := *o.breaker
+	if o.metrics == nil {
+		return settings
+	}
+
+	metrics, connector, onStateChange := o.metrics, o.metricsConnector, settings.OnStateChange
+	settings.OnStateChange = func(key string, from, to BreakerState) {
+		metrics.breakerStateChanged(connector, key, to)
+		if onStateChange != nil {
+			onStateChange(key, from, to)
+		}
+	}
+
+	return settings
+}
@@ -0,0 +1,239 @@
+package jac
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/jsonapi"
+	"github.com/pkg/errors"
+)
+
+// defaultMaxPages is a default limit of pages a Paginator requests
+const defaultMaxPages = 1000
+
+// ErrTooManyPages is returned by Paginator when it reaches the max pages limit
+var ErrTooManyPages = errors.New("max pages limit reached")
+
+// Pagination is a strategy a Paginator uses to request pages
+type Pagination interface {
+	// First returns params of the first page
+	First(params RequestParams) RequestParams
+	// Next returns params of the page that follows the received one and
+	// false if the received page is the last one. received is a number of
+	// resources on the received page, location is its final URL
+	Next(params RequestParams, document *Document, received int, location *url.URL) (RequestParams, bool)
+}
+
+type linksNextPagination struct{}
+
+// LinksNext returns Pagination that follows links.next of a document
+// until it is absent. It is the default Paginator strategy. Links to other
+// hosts than the connector base url stop the Paginator with an error
+func LinksNext() Pagination {
+	return linksNextPagination{}
+}
+
+func (linksNextPagination) First(params RequestParams) RequestParams {
+	return params
+}
+
+func (linksNextPagination) Next(params RequestParams, document *Document, _ int, location *url.URL) (RequestParams, bool) {
+	link, ok := document.Link("next")
+	if !ok {
+		return params, false
+	}
+
+	next, err := location.Parse(link)
+	if err != nil {
+		return params, false
+	}
+
+	// endpoint is kept, so the page is labelled with the same template
+	params.link = next.String()
+
+	return params, true
+}
+
+type pageNumberPagination struct {
+	size int
+}
+
+// PageNumber returns Pagination that requests pages of given size with
+// page[number] and page[size] query parameters, starting from page 1,
+// until a page with less than size resources is received
+func PageNumber(size int) Pagination {
+	return pageNumberPagination{size: size}
+}
+
+func (p pageNumberPagination) First(params RequestParams) RequestParams {
+	return params.
+		withQuery("page[number]", "1").
+		withQuery("page[size]", strconv.Itoa(p.size))
+}
+
+func (p pageNumberPagination) Next(params RequestParams, _ *Document, received int, _ *url.URL) (RequestParams, bool) {
+	if received < p.size {
+		return params, false
+	}
+
+	number, _ := strconv.Atoi(params.queryValue("page[number]"))
+	return params.withQuery("page[number]", strconv.Itoa(number+1)), true
+}
+
+type offsetLimitPagination struct {
+	limit int
+}
+
+// OffsetLimit returns Pagination that requests pages of given limit with
+// page[offset] and page[limit] query parameters, starting from offset 0,
+// until a page with less than limit resources is received
+func OffsetLimit(limit int) Pagination {
+	return offsetLimitPagination{limit: limit}
+}
+
+func (p offsetLimitPagination) First(params RequestParams) RequestParams {
+	return params.
+		withQuery("page[offset]", "0").
+		withQuery("page[limit]", strconv.Itoa(p.limit))
+}
+
+func (p offsetLimitPagination) Next(params RequestParams, _ *Document, received int, _ *url.URL) (RequestParams, bool) {
+	if received < p.limit {
+		return params, false
+	}
+
+	offset, _ := strconv.Atoi(params.queryValue("page[offset]"))
+	return params.withQuery("page[offset]", strconv.Itoa(offset+received)), true
+}
+
+type cursorPagination struct {
+	metaKey string
+}
+
+// Cursor returns Pagination that reads a cursor of the next page from
+// document meta by metaKey and sends it in page[cursor] query parameter,
+// until the cursor is absent
+func Cursor(metaKey string) Pagination {
+	return cursorPagination{metaKey: metaKey}
+}
+
+func (cursorPagination) First(params RequestParams) RequestParams {
+	return params
+}
+
+func (p cursorPagination) Next(params RequestParams, document *Document, _ int, _ *url.URL) (RequestParams, bool) {
+	cursor, ok := document.Meta[p.metaKey].(string)
+	if !ok || cursor == "" {
+		return params, false
+	}
+
+	return params.withQuery("page[cursor]", cursor), true
+}
+
+// Paginator requests pages of a JSON:API collection of T one by one.
+// T must be a struct with jsonapi tags. Use it like this:
+//
+//	paginator := NewPaginator[User](connector, RequestParams{Endpoint: "users"}, nil)
+//	for paginator.Next(ctx) {
+//		users := paginator.Page()
+//	}
+//	if err := paginator.Err(); err != nil {
+//		// handle error
+//	}
+type Paginator[T any] struct {
+	connector  Jac
+	params     RequestParams
+	pagination Pagination
+	maxPages   int
+
+	pages    int
+	done     bool
+	page     []*T
+	document *Document
+	err      error
+}
+
+// NewPaginator returns Paginator that requests pages starting from params
+// according to pagination. If pagination is nil, LinksNext is used.
+// By default, at most 1000 pages are requested
+func NewPaginator[T any](connector Jac, params RequestParams, pagination Pagination) *Paginator[T] {
+	if pagination == nil {
+		pagination = LinksNext()
+	}
+
+	return &Paginator[T]{
+		connector:  connector,
+		params:     pagination.First(params),
+		pagination: pagination,
+		maxPages:   defaultMaxPages,
+	}
+}
+
+// WithMaxPages sets a limit of pages the paginator requests.
+// When it is reached, Next returns false and Err returns ErrTooManyPages
+func (p *Paginator[T]) WithMaxPages(maxPages int) *Paginator[T] {
+	p.maxPages = maxPages
+	return p
+}
+
+// Next requests the next page. It returns false when there are no more
+// pages or an error happened, which is then returned by Err
+func (p *Paginator[T]) Next(ctx context.Context) bool {
+	if p.done {
+		return false
+	}
+	if p.pages >= p.maxPages {
+		p.done, p.err = true, ErrTooManyPages
+		return false
+	}
+
+	params := p.params.WithContext(ctx).withDefaultHeader("Accept", jsonapi.MediaType)
+	response, err := p.connector.Do(http.MethodGet, params, nil)
+	if err != nil {
+		p.done, p.err = true, errors.Wrap(err, "failed to request page")
+		return false
+	}
+
+	var page []*T
+	document, err := response.DecodeCollection(&page)
+	if err != nil {
+		p.done, p.err = true, errors.Wrap(err, "failed to decode page")
+		return false
+	}
+
+	p.pages++
+	p.page, p.document = page, document
+
+	var hasNext bool
+	p.params, hasNext = p.pagination.Next(p.params, document, len(page), response.URL)
+	p.done = !hasNext || len(page) == 0
+
+	return true
+}
+
+// Page returns resources of the page received by the last Next call
+func (p *Paginator[T]) Page() []*T {
+	return p.page
+}
+
+// Document returns top-level links and meta of the page received by the last Next call
+func (p *Paginator[T]) Document() *Document {
+	return p.document
+}
+
+// Err returns the error that stopped the paginator, if any
+func (p *Paginator[T]) Err() error {
+	return p.err
+}
+
+// All requests all remaining pages and returns their resources
+func (p *Paginator[T]) All(ctx context.Context) ([]*T, error) {
+	var all []*T
+	for p.Next(ctx) {
+		all = append(all, p.Page()...)
+	}
+
+	return all, p.Err()
+}
@@ -0,0 +1,131 @@
+package jac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/jsonapi"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPaginatedTotal = 5
+
+// newPaginatedServer returns server with testPaginatedTotal users
+// that supports all pagination strategies
+func newPaginatedServer() *httptest.Server {
+	var users []*testUser
+	for i := 0; i < testPaginatedTotal; i++ {
+		users = append(users, &testUser{ID: strconv.Itoa(i), Name: fmt.Sprintf("user-%d", i)})
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		var from, to int
+		switch {
+		case query.Has("page[number]"):
+			number, _ := strconv.Atoi(query.Get("page[number]"))
+			size, _ := strconv.Atoi(query.Get("page[size]"))
+			from, to = (number-1)*size, number*size
+		case query.Has("page[offset]"):
+			from, _ = strconv.Atoi(query.Get("page[offset]"))
+			limit, _ := strconv.Atoi(query.Get("page[limit]"))
+			to = from + limit
+		case query.Has("page[cursor]"):
+			from, _ = strconv.Atoi(query.Get("page[cursor]"))
+			to = from + 2
+		default:
+			from, _ = strconv.Atoi(query.Get("from"))
+			to = from + 2
+		}
+		if to > len(users) {
+			to = len(users)
+		}
+		if from > to {
+			from = to
+		}
+
+		payload, _ := jsonapi.Marshal(users[from:to])
+		many := payload.(*jsonapi.ManyPayload)
+		if to < len(users) {
+			many.Links = &jsonapi.Links{"next": fmt.Sprintf("%s?from=%d", r.URL.Path, to)}
+			many.Meta = &jsonapi.Meta{"next_cursor": strconv.Itoa(to)}
+		}
+
+		w.Header().Set("Content-Type", jsonapi.MediaType)
+		_ = json.NewEncoder(w).Encode(many)
+	}))
+}
+
+func TestPaginator(t *testing.T) {
+	testServer := newPaginatedServer()
+	defer testServer.Close()
+
+	var (
+		ctx     = context.Background()
+		testJac = NewJac(testServer.URL)
+		params  = RequestParams{Endpoint: "api/users"}
+	)
+
+	for name, pagination := range map[string]Pagination{
+		"links next":   nil,
+		"page number":  PageNumber(2),
+		"offset limit": OffsetLimit(2),
+		"cursor":       Cursor("next_cursor"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			users, err := NewPaginator[testUser](testJac, params, pagination).All(ctx)
+			assert.Nil(t, err)
+			assert.Len(t, users, testPaginatedTotal)
+			for i, user := range users {
+				assert.Equal(t, strconv.Itoa(i), user.ID)
+			}
+		})
+	}
+
+	t.Run("iterator", func(t *testing.T) {
+		var pages [][]*testUser
+		paginator := NewPaginator[testUser](testJac, params, nil)
+		for paginator.Next(ctx) {
+			pages = append(pages, paginator.Page())
+		}
+		assert.Nil(t, paginator.Err())
+		assert.Len(t, pages, 3)
+		assert.False(t, paginator.Next(ctx), "expected exhausted paginator to stay exhausted")
+	})
+	t.Run("max pages", func(t *testing.T) {
+		users, err := NewPaginator[testUser](testJac, params, nil).WithMaxPages(2).All(ctx)
+		assert.Equal(t, ErrTooManyPages, err)
+		assert.Len(t, users, 4)
+	})
+	t.Run("links to other hosts are not followed", func(t *testing.T) {
+		var otherHits int64
+		otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&otherHits, 1)
+		}))
+		defer otherServer.Close()
+
+		redirectingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", jsonapi.MediaType)
+			_, _ = w.Write([]byte(`{"data":[{"type":"users","id":"1","attributes":{"name":"one"}}],"links":{"next":"` + otherServer.URL + `/steal"}}`))
+		}))
+		defer redirectingServer.Close()
+
+		_, err := NewPaginator[testUser](NewJac(redirectingServer.URL, WithAuthenticator(NewBearerAuth("secret"))), params, nil).All(ctx)
+		assert.ErrorContains(t, err, "points outside of base url")
+		assert.Equal(t, int64(0), atomic.LoadInt64(&otherHits))
+	})
+	t.Run("request error", func(t *testing.T) {
+		missingServer := httptest.NewServer(http.NotFoundHandler())
+		defer missingServer.Close()
+
+		_, err := NewPaginator[testUser](NewJac(missingServer.URL), params, nil).All(ctx)
+		assert.True(t, IsNotFound(err))
+	})
+}
@@ -0,0 +1,89 @@
+package jac
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// expandPath returns a copy of params with the path of the request formed
+// from Endpoint template and PathParams. Endpoint itself is left intact,
+// so it can be used as a low-cardinality label of the request
+func (rp RequestParams) expandPath() (RequestParams, error) {
+	path, err := expandTemplate(rp.Endpoint, rp.PathParams)
+	if err != nil {
+		return rp, err
+	}
+
+	rp.path = path
+	return rp, nil
+}
+
+// expandTemplate substitutes {name} placeholders of template with path
+// escaped values from params. Every placeholder must have a value and
+// every value must have a placeholder
+func expandTemplate(template string, params map[string]string) (string, error) {
+	var (
+		result strings.Builder
+		rest   = template
+		used   = make(map[string]bool, len(params))
+	)
+
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return "", errors.Errorf("unexpected } in endpoint %q", template)
+			}
+			result.WriteString(rest)
+			break
+		}
+
+		end := strings.IndexAny(rest[start+1:], "{}")
+		if end < 0 || rest[start+1+end] != '}' {
+			return "", errors.Errorf("unclosed placeholder in endpoint %q", template)
+		}
+		end += start + 1
+
+		if strings.IndexByte(rest[:start], '}') >= 0 {
+			return "", errors.Errorf("unexpected } in endpoint %q", template)
+		}
+
+		name := rest[start+1 : end]
+		if name == "" {
+			return "", errors.Errorf("empty placeholder in endpoint %q", template)
+		}
+
+		value, ok := params[name]
+		if !ok {
+			return "", errors.Errorf("missing value of path param %q in endpoint %q", name, template)
+		}
+		if value == "" {
+			return "", errors.Errorf("empty value of path param %q in endpoint %q", name, template)
+		}
+		used[name] = true
+
+		result.WriteString(rest[:start])
+		result.WriteString(escapeSegment(value))
+		rest = rest[end+1:]
+	}
+
+	for name := range params {
+		if !used[name] {
+			return "", errors.Errorf("path param %q is not used in endpoint %q", name, template)
+		}
+	}
+
+	return result.String(), nil
+}
+
+// escapeSegment escapes value as a single path segment. Dot segments are
+// escaped too, so they are not resolved against the parent segment
+func escapeSegment(value string) string {
+	if value == "." || value == ".." {
+		return strings.ReplaceAll(value, ".", "%2E")
+	}
+
+	return url.PathEscape(value)
+}
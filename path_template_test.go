@@ -0,0 +1,128 @@
+package jac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   map[string]string
+		expected string
+		err      string
+	}{
+		{
+			name:     "no placeholders",
+			template: "users",
+			expected: "users",
+		},
+		{
+			name:     "escaped segments",
+			template: "users/{id}/keys/{kid}",
+			params:   map[string]string{"id": "a/b?c#d", "kid": "key 1"},
+			expected: "users/a%2Fb%3Fc%23d/keys/key%201",
+		},
+		{
+			name:     "dot segment",
+			template: "users/{id}",
+			params:   map[string]string{"id": ".."},
+			expected: "users/%2E%2E",
+		},
+		{
+			name:     "missing param",
+			template: "users/{id}/keys/{kid}",
+			params:   map[string]string{"id": "1"},
+			err:      `missing value of path param "kid"`,
+		},
+		{
+			name:     "unused param",
+			template: "users/{id}",
+			params:   map[string]string{"id": "1", "kid": "2"},
+			err:      `path param "kid" is not used`,
+		},
+		{
+			name:     "empty value",
+			template: "users/{id}",
+			params:   map[string]string{"id": ""},
+			err:      `empty value of path param "id"`,
+		},
+		{
+			name:     "unclosed placeholder",
+			template: "users/{id",
+			params:   map[string]string{"id": "1"},
+			err:      "unclosed placeholder",
+		},
+		{
+			name:     "nested placeholder",
+			template: "users/{{id}}",
+			params:   map[string]string{"id": "1"},
+			err:      "unclosed placeholder",
+		},
+		{
+			name:     "unexpected brace",
+			template: "users/id}",
+			err:      "unexpected }",
+		},
+		{
+			name:     "empty placeholder",
+			template: "users/{}",
+			err:      "empty placeholder",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := expandTemplate(tt.template, tt.params)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, path)
+		})
+	}
+}
+
+func TestJacer_PathParams(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`"` + r.URL.EscapedPath() + `"`))
+	}))
+	defer testServer.Close()
+
+	testJac := NewJac(testServer.URL + "/api")
+
+	t.Run("escaped", func(t *testing.T) {
+		var path string
+		_, err := testJac.Get(RequestParams{
+			Endpoint:   "users/{id}/keys/{kid}",
+			PathParams: map[string]string{"id": "john/doe", "kid": "a?b#c"},
+		}, &path)
+		assert.Nil(t, err)
+		assert.Equal(t, "/api/users/john%2Fdoe/keys/a%3Fb%23c", path)
+	})
+
+	t.Run("cannot replace base url", func(t *testing.T) {
+		var path string
+		_, err := testJac.Get(RequestParams{
+			Endpoint:   "{id}",
+			PathParams: map[string]string{"id": "http:evil"},
+		}, &path)
+		assert.Nil(t, err)
+		assert.Equal(t, "/api/http:evil", path)
+
+		_, err = testJac.Get(RequestParams{Endpoint: "v1:batch"}, &path)
+		assert.Nil(t, err)
+		assert.Equal(t, "/api/v1:batch", path)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := testJac.Get(RequestParams{Endpoint: "users/{id}"}, nil)
+		assert.ErrorContains(t, err, `missing value of path param "id" in endpoint "users/{id}"`)
+	})
+}
@@ -0,0 +1,96 @@
+package jac
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// QueryBuilder builds JSON:API query parameters, for example:
+//
+//	Query().Filter("owner", id).Sort("-created_at").Include("author").PageLimit(50)
+type QueryBuilder struct {
+	values url.Values
+}
+
+// Query returns an empty QueryBuilder
+func Query() *QueryBuilder {
+	return &QueryBuilder{values: url.Values{}}
+}
+
+// Filter sets filter[key] parameter. Multiple values are joined with a comma
+func (q *QueryBuilder) Filter(key string, values ...string) *QueryBuilder {
+	return q.Set(fmt.Sprintf("filter[%s]", key), strings.Join(values, ","))
+}
+
+// Sort appends fields to sort parameter. Prefix a field with "-" for descending order
+func (q *QueryBuilder) Sort(fields ...string) *QueryBuilder {
+	return q.appendList("sort", fields)
+}
+
+// Include appends relationship paths to include parameter
+func (q *QueryBuilder) Include(paths ...string) *QueryBuilder {
+	return q.appendList("include", paths)
+}
+
+// Fields appends fields to fields[resourceType] sparse fieldset parameter
+func (q *QueryBuilder) Fields(resourceType string, fields ...string) *QueryBuilder {
+	return q.appendList(fmt.Sprintf("fields[%s]", resourceType), fields)
+}
+
+// PageLimit sets page[limit] parameter
+func (q *QueryBuilder) PageLimit(limit int) *QueryBuilder {
+	return q.Set("page[limit]", strconv.Itoa(limit))
+}
+
+// PageOffset sets page[offset] parameter
+func (q *QueryBuilder) PageOffset(offset int) *QueryBuilder {
+	return q.Set("page[offset]", strconv.Itoa(offset))
+}
+
+// PageNumber sets page[number] parameter
+func (q *QueryBuilder) PageNumber(number int) *QueryBuilder {
+	return q.Set("page[number]", strconv.Itoa(number))
+}
+
+// PageSize sets page[size] parameter
+func (q *QueryBuilder) PageSize(size int) *QueryBuilder {
+	return q.Set("page[size]", strconv.Itoa(size))
+}
+
+// PageCursor sets page[cursor] parameter
+func (q *QueryBuilder) PageCursor(cursor string) *QueryBuilder {
+	return q.Set("page[cursor]", cursor)
+}
+
+// Set sets key parameter to value, replacing existing values
+func (q *QueryBuilder) Set(key, value string) *QueryBuilder {
+	q.values.Set(key, value)
+	return q
+}
+
+// Add adds value to key parameter, so the key is repeated in the query
+func (q *QueryBuilder) Add(key, value string) *QueryBuilder {
+	q.values.Add(key, value)
+	return q
+}
+
+// Values returns built parameters to be used as RequestParams.QueryValues
+func (q *QueryBuilder) Values() url.Values {
+	return q.values
+}
+
+// Encode returns built parameters in URL-encoded form
+func (q *QueryBuilder) Encode() string {
+	return q.values.Encode()
+}
+
+// appendList appends items to a comma-separated list parameter
+func (q *QueryBuilder) appendList(key string, items []string) *QueryBuilder {
+	if existing := q.values.Get(key); existing != "" {
+		items = append([]string{existing}, items...)
+	}
+
+	return q.Set(key, strings.Join(items, ","))
+}
@@ -0,0 +1,56 @@
+package jac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	query := Query().
+		Filter("owner", "42").
+		Filter("status", "active", "pending").
+		Sort("-created_at").
+		Sort("name").
+		Include("author", "comments.author").
+		Fields("users", "name", "email").
+		PageLimit(50).
+		Add("id", "1").
+		Add("id", "2")
+
+	assert.Equal(t, url.Values{
+		"filter[owner]":  {"42"},
+		"filter[status]": {"active,pending"},
+		"sort":           {"-created_at,name"},
+		"include":        {"author,comments.author"},
+		"fields[users]":  {"name,email"},
+		"page[limit]":    {"50"},
+		"id":             {"1", "2"},
+	}, query.Values())
+}
+
+func TestJacer_QueryValues(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`"` + r.URL.RawQuery + `"`))
+	}))
+	defer testServer.Close()
+
+	var rawQuery string
+	_, err := NewJac(testServer.URL).Get(RequestParams{
+		Endpoint:    "users",
+		Query:       map[string]string{"legacy": "yes"},
+		QueryValues: Query().Add("id", "1").Add("id", "2").Fields("users", "name").Values(),
+	}, &rawQuery)
+	assert.Nil(t, err)
+
+	query, err := url.ParseQuery(rawQuery)
+	assert.Nil(t, err)
+	assert.Equal(t, url.Values{
+		"legacy":        {"yes"},
+		"id":            {"1", "2"},
+		"fields[users]": {"name"},
+	}, query)
+}
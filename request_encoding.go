@@ -0,0 +1,241 @@
+package jac
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// pathTag marks a field that fills {name} placeholder of the endpoint template
+	pathTag = "path"
+	// queryTag marks a field that is sent as a query parameter
+	queryTag = "query"
+	// headerTag marks a field that is sent as a header
+	headerTag = "header"
+)
+
+// encodeInput returns a copy of params with Input encoded into path params,
+// query and headers. Input must be a struct or a pointer to a struct with
+// fields tagged like this:
+//
+//	type GetUser struct {
+//		ID      string   `path:"id"`
+//		Include []string `query:"include,comma"`
+//		Tenant  string   `header:"X-Tenant"`
+//	}
+//
+// Path values fill placeholders of the endpoint template. Zero query and
+// header values are skipped, use pointers to send them. Slices are sent as
+// repeated query parameters and headers, or as a comma-separated list with
+// the comma option. Path params and headers
+// already set in params take precedence. Embedded structs are encoded too
+func (rp RequestParams) encodeInput() (RequestParams, error) {
+	if rp.Input == nil {
+		return rp, nil
+	}
+
+	value := reflect.ValueOf(rp.Input)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return rp, nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return rp, errors.Errorf("expected input to be a struct, got %T", rp.Input)
+	}
+
+	encoded := encodedInput{
+		path:   map[string]string{},
+		query:  url.Values{},
+		header: http.Header{},
+	}
+	if err := encoded.encodeStruct(value); err != nil {
+		return rp, errors.Wrap(err, "failed to encode input")
+	}
+
+	if len(encoded.path) != 0 {
+		pathParams := make(map[string]string, len(rp.PathParams)+len(encoded.path))
+		for key, value := range encoded.path {
+			pathParams[key] = value
+		}
+		for key, value := range rp.PathParams {
+			pathParams[key] = value
+		}
+		rp.PathParams = pathParams
+	}
+
+	if len(encoded.query) != 0 {
+		values := make(url.Values, len(rp.QueryValues)+len(encoded.query))
+		for key, value := range rp.QueryValues {
+			values[key] = value
+		}
+		for key, value := range encoded.query {
+			values[key] = append(append([]string(nil), values[key]...), value...)
+		}
+		rp.QueryValues = values
+	}
+	if len(encoded.header) != 0 {
+		header := make(http.Header, len(rp.HeaderValues)+len(encoded.header))
+		for key, values := range rp.HeaderValues {
+			header[key] = values
+		}
+		for key, values := range encoded.header {
+			if !rp.hasHeader(key) {
+				header[key] = values
+			}
+		}
+		rp.HeaderValues = header
+	}
+	rp.Input = nil
+
+	return rp, nil
+}
+
+// encodedInput contains values collected from input fields
+type encodedInput struct {
+	path   map[string]string
+	query  url.Values
+	header http.Header
+}
+
+// encodeStruct collects tagged fields of struct value
+func (e encodedInput) encodeStruct(value reflect.Value) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+
+		if field.Anonymous && field.Tag == "" {
+			embedded := reflect.Indirect(fieldValue)
+			if embedded.Kind() == reflect.Struct {
+				if err := e.encodeStruct(embedded); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if err := e.encodeField(field, fieldValue); err != nil {
+			return errors.Wrapf(err, "failed to encode field %s", field.Name)
+		}
+	}
+
+	return nil
+}
+
+// encodeField collects a value of a single tagged field
+func (e encodedInput) encodeField(field reflect.StructField, value reflect.Value) error {
+	if name, ok := field.Tag.Lookup(pathTag); ok {
+		values, err := formatValues(value)
+		if err != nil {
+			return err
+		}
+		if len(values) != 1 {
+			return errors.Errorf("expected single value for path parameter %q", name)
+		}
+
+		e.path[name] = values[0]
+		return nil
+	}
+
+	if tag, ok := field.Tag.Lookup(queryTag); ok {
+		name, options, _ := strings.Cut(tag, ",")
+		values, err := formatValues(value)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case len(values) == 0:
+		case options == "comma":
+			e.query.Set(name, strings.Join(values, ","))
+		default:
+			e.query[name] = values
+		}
+		return nil
+	}
+
+	if tag, ok := field.Tag.Lookup(headerTag); ok {
+		name, options, _ := strings.Cut(tag, ",")
+		values, err := formatValues(value)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case len(values) == 0:
+		case options == "comma":
+			e.header.Set(name, strings.Join(values, ", "))
+		default:
+			e.header[http.CanonicalHeaderKey(name)] = values
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// formatValues formats value as a list of strings. Zero values and nil
+// pointers produce an empty list, while pointers to zero values do not.
+// Slices produce a value per element
+func formatValues(value reflect.Value) ([]string, error) {
+	pointer := value.Kind() == reflect.Pointer
+	if pointer {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		var values []string
+		for i := 0; i < value.Len(); i++ {
+			formatted, err := formatValue(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, formatted)
+		}
+		return values, nil
+	}
+
+	if !pointer && value.IsZero() {
+		return nil, nil
+	}
+
+	formatted, err := formatValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{formatted}, nil
+}
+
+// formatValue formats a single scalar value
+func formatValue(value reflect.Value) (string, error) {
+	switch v := value.Interface().(type) {
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	switch value.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(value.Interface()), nil
+	default:
+		return "", errors.Errorf("unsupported value type %s", value.Type())
+	}
+}
@@ -0,0 +1,86 @@
+package jac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPage struct {
+	Limit int `query:"page[limit]"`
+}
+
+type testGetUser struct {
+	testPage
+	ID      string   `path:"id"`
+	Include []string `query:"include,comma"`
+	IDs     []int    `query:"id"`
+	Tenant  string   `header:"X-Tenant"`
+	Prefer  []string `header:"prefer"`
+	Accept  string   `header:"Accept"`
+	Active  *bool    `query:"active"`
+	ignored string
+}
+
+func TestRequestParams_EncodeInput(t *testing.T) {
+	active := false
+
+	params, err := RequestParams{
+		Endpoint: "users/{id}/keys",
+		Header:   map[string]string{"Accept": "application/json"},
+		Input: &testGetUser{
+			testPage: testPage{Limit: 10},
+			ID:       "a/b?c#d",
+			Include:  []string{"keys", "roles"},
+			IDs:      []int{1, 2},
+			Tenant:   "acme",
+			Prefer:   []string{"return=minimal", "wait=5"},
+			Accept:   "text/plain",
+			Active:   &active,
+		},
+	}.encodeInput()
+	assert.Nil(t, err)
+
+	assert.Equal(t, "users/{id}/keys", params.Endpoint)
+	assert.Equal(t, map[string]string{"id": "a/b?c#d"}, params.PathParams)
+	assert.Equal(t, url.Values{
+		"page[limit]": {"10"},
+		"include":     {"keys,roles"},
+		"id":          {"1", "2"},
+		"active":      {"false"},
+	}, params.QueryValues)
+	assert.Equal(t, map[string]string{"Accept": "application/json"}, params.Header)
+	assert.Equal(t, http.Header{
+		"X-Tenant": {"acme"},
+		"Prefer":   {"return=minimal", "wait=5"},
+	}, params.HeaderValues)
+	assert.Nil(t, params.Input)
+}
+
+func TestRequestParams_EncodeInputErrors(t *testing.T) {
+	_, err := RequestParams{Endpoint: "users", Input: "id"}.encodeInput()
+	assert.ErrorContains(t, err, "expected input to be a struct")
+
+	_, err = RequestParams{Endpoint: "users", Input: struct {
+		Filter map[string]string `query:"filter"`
+	}{Filter: map[string]string{"a": "b"}}}.encodeInput()
+	assert.ErrorContains(t, err, "unsupported value type")
+}
+
+func TestJacer_Input(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`"` + r.URL.EscapedPath() + " " + r.URL.RawQuery + " " + r.Header.Get("X-Tenant") + `"`))
+	}))
+	defer testServer.Close()
+
+	var result string
+	_, err := NewJac(testServer.URL).Get(RequestParams{
+		Endpoint: "users/{id}",
+		Input:    testGetUser{ID: "john/doe", Include: []string{"keys"}, Tenant: "acme"},
+	}, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, "/users/john%2Fdoe include=keys acme", result)
+}
@@ -0,0 +1,84 @@
+package jac
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/oklog/ulid"
+	"gitlab.com/distributed_lab/ape"
+)
+
+// defaultRequestIDHeader is a header request id is sent in if none is specified
+const defaultRequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying request id,
+// which connectors send with requests made with this context
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns request id carried by ctx. Besides the id set
+// with ContextWithRequestID, the ones set by chi and ape middlewares are used
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		return id, true
+	}
+	if id := middleware.GetReqID(ctx); id != "" {
+		return id, true
+	}
+	if id := apeRequestID(ctx); id != "" {
+		return id, true
+	}
+
+	return "", false
+}
+
+// NewRequestID returns a new ULID request id
+func NewRequestID() string {
+	return ulid.MustNew(ulid.Now(), rand.Reader).String()
+}
+
+// apeRequestID returns request id set by ape middlewares.
+// ape.GetRequestID panics if there is none, so the panic is recovered
+func apeRequestID(ctx context.Context) (id string) {
+	defer func() {
+		if recover() != nil {
+			id = ""
+		}
+	}()
+
+	return ape.GetRequestID(ctx)
+}
+
+// withRequestID returns a copy of params with request id sent in the request
+// id header and put into the context, and the id itself. The id is taken from
+// the header set per request, from the context or generated anew
+func (c *jac) withRequestID(params RequestParams) (RequestParams, string) {
+	if c.requestIDHeader == "" {
+		return params, ""
+	}
+
+	id := params.requestHeader().Get(c.requestIDHeader)
+	if id == "" {
+		id, _ = RequestIDFromContext(params.ctx())
+	}
+	if id == "" {
+		id = NewRequestID()
+	}
+
+	if !params.hasHeader(c.requestIDHeader) {
+		header := params.HeaderValues.Clone()
+		if header == nil {
+			header = make(http.Header, 1)
+		}
+		header.Set(c.requestIDHeader, id)
+		params.HeaderValues = header
+	}
+	params.Context = ContextWithRequestID(params.ctx(), id)
+
+	return params, id
+}
@@ -0,0 +1,111 @@
+package jac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/oklog/ulid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDFromContext(t *testing.T) {
+	_, ok := RequestIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	id, ok := RequestIDFromContext(ContextWithRequestID(context.Background(), "req-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "req-1", id)
+
+	id, ok = RequestIDFromContext(context.WithValue(context.Background(), middleware.RequestIDKey, "chi-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "chi-1", id)
+
+	_, err := ulid.Parse(NewRequestID())
+	assert.Nil(t, err)
+}
+
+func TestJacer_RequestID(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		ids []string
+	)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids = append(ids, r.Header.Get("X-Request-ID"), r.Header.Get("X-Correlation-ID"))
+		mu.Unlock()
+
+		if r.URL.Path == "/echo" {
+			w.Header().Set("X-Request-ID", "server-1")
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	lastIDs := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return ids[len(ids)-2:]
+	}
+
+	t.Run("generated", func(t *testing.T) {
+		response, err := NewJac(testServer.URL).Do(http.MethodGet, RequestParams{}, nil)
+		assert.Nil(t, err)
+
+		_, err = ulid.Parse(lastIDs()[0])
+		assert.Nil(t, err)
+		assert.Equal(t, lastIDs()[0], response.RequestID)
+	})
+	t.Run("from context", func(t *testing.T) {
+		ctx := ContextWithRequestID(context.Background(), "req-1")
+		response, err := NewJac(testServer.URL).Do(http.MethodGet, RequestParams{Context: ctx}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"req-1", ""}, lastIDs())
+		assert.Equal(t, "req-1", response.RequestID)
+	})
+	t.Run("set per request", func(t *testing.T) {
+		ctx := ContextWithRequestID(context.Background(), "req-1")
+		_, err := NewJac(testServer.URL).Do(http.MethodGet, RequestParams{
+			Context: ctx,
+			Header:  map[string]string{"X-Request-ID": "req-2"},
+		}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"req-2", ""}, lastIDs())
+	})
+	t.Run("custom header", func(t *testing.T) {
+		ctx := ContextWithRequestID(context.Background(), "req-1")
+		_, err := NewJac(testServer.URL, WithRequestIDHeader("X-Correlation-ID")).Do(http.MethodGet, RequestParams{Context: ctx}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"", "req-1"}, lastIDs())
+	})
+	t.Run("disabled", func(t *testing.T) {
+		response, err := NewJac(testServer.URL, WithRequestIDHeader("")).Do(http.MethodGet, RequestParams{}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"", ""}, lastIDs())
+		assert.Empty(t, response.RequestID)
+	})
+	t.Run("echoed by server", func(t *testing.T) {
+		ctx := ContextWithRequestID(context.Background(), "req-1")
+		_, err := NewJac(testServer.URL).Do(http.MethodGet, RequestParams{Endpoint: "echo", Context: ctx}, nil)
+		assert.True(t, IsNotFound(err))
+		assert.EqualError(t, err, "api responded with status 404 Not Found to request server-1: Not Found")
+	})
+	t.Run("logged", func(t *testing.T) {
+		log, buffer := newTestLog()
+		ctx := ContextWithRequestID(context.Background(), "req-1")
+		_, err := NewJac(testServer.URL, WithLogger(log, LogSettings{})).Get(RequestParams{Context: ctx}, nil)
+		assert.Nil(t, err)
+
+		entries := logEntries(t, buffer)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "req-1", entries[0]["request_id"])
+	})
+	t.Run("in send errors", func(t *testing.T) {
+		ctx := ContextWithRequestID(context.Background(), "req-1")
+		_, err := NewJac("http://127.0.0.1:1").Get(RequestParams{Context: ctx}, nil)
+		assert.ErrorContains(t, err, "failed to send request req-1")
+	})
+}
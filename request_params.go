@@ -1,16 +1,71 @@
 package jac
 
 import (
+	"bytes"
+	"context"
 	"net/http"
+	"net/url"
+
+	"github.com/google/jsonapi"
+	"github.com/pkg/errors"
 )
 
 // RequestParams is a structure for performing different requests
 type RequestParams struct {
-	method   string
+	method string
+	// path is the endpoint expanded with PathParams
+	path string
+	// link is an absolute URL that is requested instead of the endpoint
+	// with the query, e.g. a link to the next page
+	link string
+	// defaultHeader contains headers the package sets by default, e.g.
+	// Accept of JSON:API requests. Connector and request headers override them
+	defaultHeader http.Header
+	// Endpoint is a path relative to the base url.
+	// It can be a template like "users/{id}/keys/{kid}" filled with
+	// PathParams, so it stays a low-cardinality label of the request
 	Endpoint string
-	Body     []byte
-	Query    map[string]string
-	Header   map[string]string
+	// PathParams are values of Endpoint placeholders. Every value is
+	// escaped as a single path segment, so it can contain /, ? or #.
+	// Missing and unused placeholders are rejected
+	PathParams map[string]string
+	Body       []byte
+	// Query and QueryValues are combined. Their keys replace the ones
+	// from the base url query
+	Query map[string]string
+	// Header and HeaderValues are combined. Their keys replace the ones
+	// set by the connector, e.g. credentials of the authenticator
+	Header map[string]string
+	// QueryValues are added to the query after Query, so keys can be
+	// repeated. Use Query() builder to form JSON:API parameters
+	QueryValues url.Values
+	// HeaderValues are added to the headers after Header, so keys can be
+	// repeated, e.g. several Accept values
+	HeaderValues http.Header
+	// Payload is a model or a slice of models with jsonapi tags which is
+	// marshalled into a JSON:API document and sent as a request body with
+	// application/vnd.api+json content type. It cannot be used with Body
+	Payload any
+	// Input is a struct with path, query and header tags which fills
+	// PathParams, query parameters and headers
+	Input any
+	// Context is used to cancel a request or to attach a deadline to it.
+	// If nil, context.Background() is used
+	Context context.Context
+}
+
+// WithContext returns a shallow copy of params with its context changed to ctx
+func (rp RequestParams) WithContext(ctx context.Context) RequestParams {
+	rp.Context = ctx
+	return rp
+}
+
+func (rp RequestParams) ctx() context.Context {
+	if rp.Context == nil {
+		return context.Background()
+	}
+
+	return rp.Context
 }
 
 func (rp RequestParams) addMethod(method string) RequestParams {
@@ -18,26 +73,150 @@ func (rp RequestParams) addMethod(method string) RequestParams {
 	return rp
 }
 
-func (rp RequestParams) addRequestQuery(r *http.Request) *http.Request {
-	if rp.Query != nil {
-		q := r.URL.Query()
+// withQuery returns a copy of params with query parameter key set to value
+func (rp RequestParams) withQuery(key, value string) RequestParams {
+	if _, ok := rp.Query[key]; ok {
+		query := make(map[string]string, len(rp.Query))
+		for k, v := range rp.Query {
+			if k != key {
+				query[k] = v
+			}
+		}
+		rp.Query = query
+	}
+
+	values := make(url.Values, len(rp.QueryValues)+1)
+	for k, v := range rp.QueryValues {
+		values[k] = v
+	}
+
+	values.Set(key, value)
+	rp.QueryValues = values
 
-		for key, value := range rp.Query {
-			q.Add(key, value)
+	return rp
+}
+
+// queryValue returns the first value of query parameter key
+func (rp RequestParams) queryValue(key string) string {
+	if rp.QueryValues.Has(key) {
+		return rp.QueryValues.Get(key)
+	}
+
+	return rp.Query[key]
+}
+
+// withDefaultHeader returns a copy of params with default header set to value.
+// It is sent unless the connector or params set the header
+func (rp RequestParams) withDefaultHeader(key, value string) RequestParams {
+	header := rp.defaultHeader.Clone()
+	if header == nil {
+		header = make(http.Header, 1)
+	}
+
+	header.Set(key, value)
+	rp.defaultHeader = header
+
+	return rp
+}
+
+// hasHeader reports whether header key is set in Header or HeaderValues
+func (rp RequestParams) hasHeader(key string) bool {
+	key = http.CanonicalHeaderKey(key)
+
+	for k := range rp.Header {
+		if http.CanonicalHeaderKey(k) == key {
+			return true
 		}
+	}
+	for k := range rp.HeaderValues {
+		if http.CanonicalHeaderKey(k) == key {
+			return true
+		}
+	}
 
-		r.URL.RawQuery = q.Encode()
+	return false
+}
+
+// encodePayload returns a copy of params with Payload marshalled into Body
+func (rp RequestParams) encodePayload() (RequestParams, error) {
+	if rp.Payload == nil {
+		return rp, nil
+	}
+	if rp.Body != nil {
+		return rp, errors.New("body and payload cannot be used together")
 	}
 
-	return r
+	var body bytes.Buffer
+	if err := jsonapi.MarshalPayload(&body, rp.Payload); err != nil {
+		return rp, errors.Wrap(err, "failed to marshal payload")
+	}
+
+	rp.Body = body.Bytes()
+	rp.Payload = nil
+
+	return rp.withDefaultHeader("Content-Type", jsonapi.MediaType), nil
 }
 
-func (rp RequestParams) addRequestHeaders(r *http.Request) *http.Request {
-	if rp.Header != nil {
-		for key, value := range rp.Header {
-			r.Header.Set(key, value)
+// requestQuery returns Query combined with QueryValues
+func (rp RequestParams) requestQuery() url.Values {
+	query := make(url.Values, len(rp.Query)+len(rp.QueryValues))
+
+	for key, value := range rp.Query {
+		query.Add(key, value)
+	}
+	for key, values := range rp.QueryValues {
+		for _, value := range values {
+			query.Add(key, value)
 		}
 	}
 
+	return query
+}
+
+// requestHeader returns Header combined with HeaderValues
+func (rp RequestParams) requestHeader() http.Header {
+	header := make(http.Header, len(rp.Header)+len(rp.HeaderValues))
+
+	for key, value := range rp.Header {
+		header.Add(key, value)
+	}
+	for key, values := range rp.HeaderValues {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	return header
+}
+
+// addRequestQuery adds params query to the request. Values of a key
+// replace the ones the request url already has
+func (rp RequestParams) addRequestQuery(r *http.Request) *http.Request {
+	query := rp.requestQuery()
+	if len(query) == 0 {
+		return r
+	}
+
+	q := r.URL.Query()
+	for key, values := range query {
+		q[key] = values
+	}
+	r.URL.RawQuery = q.Encode()
+
 	return r
 }
+
+// addRequestHeaders adds params headers to the request. Values of a key
+// replace the ones the request already has
+func (rp RequestParams) addRequestHeaders(r *http.Request) *http.Request {
+	setHeader(r.Header, rp.requestHeader())
+	return r
+}
+
+// setHeader sets all values of every key in src to dst,
+// replacing the ones dst has
+func setHeader(dst, src http.Header) {
+	for key, values := range src {
+		dst[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+}
@@ -0,0 +1,102 @@
+package jac
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJacer_MergeQueryAndHeaders(t *testing.T) {
+	type echo struct {
+		Query  url.Values  `json:"query"`
+		Header http.Header `json:"header"`
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(echo{Query: r.URL.Query(), Header: r.Header})
+	}))
+	defer testServer.Close()
+
+	testJac := NewJac(testServer.URL+"?key=base&tenant=acme", WithAuthenticator(NewBearerAuth("token")))
+
+	var result echo
+	_, err := testJac.Get(RequestParams{
+		Query:        map[string]string{"key": "first"},
+		QueryValues:  url.Values{"key": {"second", "third"}},
+		Header:       map[string]string{"Accept": "application/vnd.api+json"},
+		HeaderValues: http.Header{"Accept": {"application/json"}, "Prefer": {"a", "b"}},
+	}, &result)
+	assert.Nil(t, err)
+
+	// request keys replace base url ones, the rest are kept
+	assert.Equal(t, url.Values{"key": {"first", "second", "third"}, "tenant": {"acme"}}, result.Query)
+
+	assert.Equal(t, []string{"application/vnd.api+json", "application/json"}, result.Header.Values("Accept"))
+	assert.Equal(t, []string{"a", "b"}, result.Header.Values("Prefer"))
+	assert.Equal(t, "Bearer token", result.Header.Get("Authorization"))
+
+	t.Run("override credentials", func(t *testing.T) {
+		var result echo
+		_, err := testJac.Get(RequestParams{HeaderValues: http.Header{"Authorization": {"Bearer other"}}}, &result)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Bearer other"}, result.Header.Values("Authorization"))
+	})
+}
+
+func TestRequestParams_WithDefaultHeader(t *testing.T) {
+	params := RequestParams{Header: map[string]string{"X-Tenant": "acme"}}
+	withDefault := params.withDefaultHeader("accept", "application/json")
+
+	assert.Equal(t, params.Header, withDefault.Header)
+	assert.Equal(t, http.Header{"Accept": {"application/json"}}, withDefault.defaultHeader)
+	assert.Nil(t, params.defaultHeader)
+}
+
+func TestJacer_DefaultHeaders(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(r.Header)
+	}))
+	defer testServer.Close()
+
+	t.Run("user agent", func(t *testing.T) {
+		var header http.Header
+		_, err := NewJac(testServer.URL, WithServiceName("users")).Get(RequestParams{}, &header)
+		assert.Nil(t, err)
+		assert.Equal(t, "jac/"+Version+" (users)", header.Get("User-Agent"))
+
+		_, err = NewJac(testServer.URL, WithUserAgent("users/2.0")).Get(RequestParams{}, &header)
+		assert.Nil(t, err)
+		assert.Equal(t, "users/2.0", header.Get("User-Agent"))
+	})
+
+	testJac := NewJac(testServer.URL,
+		WithHeaders(http.Header{"X-Tenant": {"acme"}, "Accept": {"application/json"}}),
+		WithAuthenticator(NewAPIKeyAuth("X-Tenant", "key")),
+	)
+
+	t.Run("credentials override defaults", func(t *testing.T) {
+		var header http.Header
+		_, err := testJac.Get(RequestParams{}, &header)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"key"}, header.Values("X-Tenant"))
+		assert.Equal(t, []string{"application/json"}, header.Values("Accept"))
+	})
+
+	t.Run("request overrides defaults", func(t *testing.T) {
+		var header http.Header
+		_, err := testJac.Get(RequestParams{Header: map[string]string{"Accept": "text/plain"}}, &header)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"text/plain"}, header.Values("Accept"))
+	})
+
+	t.Run("connector overrides package defaults", func(t *testing.T) {
+		var header http.Header
+		_, err := testJac.Get(RequestParams{}.withDefaultHeader("Accept", "application/vnd.api+json"), &header)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"application/json"}, header.Values("Accept"))
+	})
+}
@@ -0,0 +1,170 @@
+package jac
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Resource is a typed client of a JSON:API resource. T must be a struct with
+// jsonapi tags. Resources are addressed according to JSON:API conventions:
+// /{type} for the collection and /{type}/{id} for a single resource.
+// Error responses come back as *APIError
+type Resource[T any] struct {
+	connector Jac
+	endpoint  string
+}
+
+// NewResource returns a client of resources of type T sent through connector.
+// Collection endpoint is the resource type from T's jsonapi primary tag.
+// It panics if T has no primary tag
+func NewResource[T any](connector Jac) *Resource[T] {
+	resourceType, ok := primaryType(reflect.TypeOf((*T)(nil)).Elem())
+	if !ok {
+		panic(fmt.Sprintf("%T has no jsonapi primary tag", *new(T)))
+	}
+
+	return &Resource[T]{
+		connector: connector,
+		endpoint:  resourceType,
+	}
+}
+
+// At returns a copy of the resource client with collection endpoint
+// changed to endpoint, e.g. "api/v2/users"
+func (r *Resource[T]) At(endpoint string) *Resource[T] {
+	return &Resource[T]{
+		connector: r.connector,
+		endpoint:  endpoint,
+	}
+}
+
+// List returns resources of the collection filtered with query,
+// which can be built with Query() builder
+func (r *Resource[T]) List(ctx context.Context, query url.Values) ([]*T, error) {
+	var models []*T
+	if _, err := r.connector.GetCollection(RequestParams{Endpoint: r.endpoint, QueryValues: query, Context: ctx}, &models); err != nil {
+		return nil, errors.Wrap(err, "failed to list resources")
+	}
+
+	return models, nil
+}
+
+// Get returns a resource with given id
+func (r *Resource[T]) Get(ctx context.Context, id string) (*T, error) {
+	model := new(T)
+	if _, err := r.connector.GetResource(r.resourceParams(ctx, id), model); err != nil {
+		return nil, errors.Wrap(err, "failed to get resource")
+	}
+
+	return model, nil
+}
+
+// Create creates a resource and returns the one the server responded with.
+// If the server responds with no content, model itself is returned
+func (r *Resource[T]) Create(ctx context.Context, model *T) (*T, error) {
+	response, err := r.connector.Do(http.MethodPost, RequestParams{Endpoint: r.endpoint, Payload: model, Context: ctx}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create resource")
+	}
+
+	return decodeModel(response, model)
+}
+
+// Update updates a resource with given id with attributes of patch and returns
+// the one the server responded with. All attributes of patch are sent, so
+// attributes that must be left as is should be tagged with omitempty.
+// Patch is not modified: a copy of it with id set is sent, and the copy
+// is returned if the server responds with no content
+func (r *Resource[T]) Update(ctx context.Context, id string, patch *T) (*T, error) {
+	patched := *patch
+	setPrimaryID(&patched, id)
+
+	params := r.resourceParams(ctx, id)
+	params.Payload = &patched
+
+	response, err := r.connector.Do(http.MethodPatch, params, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update resource")
+	}
+
+	return decodeModel(response, &patched)
+}
+
+// Delete deletes a resource with given id
+func (r *Resource[T]) Delete(ctx context.Context, id string) error {
+	if _, err := r.connector.Do(http.MethodDelete, r.resourceParams(ctx, id), nil); err != nil {
+		return errors.Wrap(err, "failed to delete resource")
+	}
+
+	return nil
+}
+
+// resourceParams returns params of a request to a resource with given id
+func (r *Resource[T]) resourceParams(ctx context.Context, id string) RequestParams {
+	return RequestParams{
+		Endpoint:   path.Join(r.endpoint, "{id}"),
+		PathParams: map[string]string{"id": id},
+		Context:    ctx,
+	}
+}
+
+// decodeModel decodes a resource from response or returns fallback if response has no content
+func decodeModel[T any](response *Response, fallback *T) (*T, error) {
+	if response.StatusCode == http.StatusNoContent || len(response.Body) == 0 {
+		return fallback, nil
+	}
+
+	model := new(T)
+	if _, err := response.DecodeResource(model); err != nil {
+		return nil, errors.Wrap(err, "failed to decode resource")
+	}
+
+	return model, nil
+}
+
+// primaryType returns a resource type from jsonapi primary tag of struct t
+func primaryType(t reflect.Type) (string, bool) {
+	if _, tag, ok := primaryField(t); ok {
+		return tag, true
+	}
+
+	return "", false
+}
+
+// setPrimaryID sets string primary field of model to id if it is empty
+func setPrimaryID(model any, id string) {
+	value := reflect.ValueOf(model).Elem()
+
+	index, _, ok := primaryField(value.Type())
+	if !ok {
+		return
+	}
+
+	field := value.Field(index)
+	if field.Kind() == reflect.String && field.String() == "" {
+		field.SetString(id)
+	}
+}
+
+// primaryField returns index and resource type of a field with jsonapi primary tag
+func primaryField(t reflect.Type) (int, string, bool) {
+	if t.Kind() != reflect.Struct {
+		return 0, "", false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		args := strings.Split(t.Field(i).Tag.Get("jsonapi"), ",")
+		if len(args) >= 2 && args[0] == "primary" {
+			return i, args[1], true
+		}
+	}
+
+	return 0, "", false
+}
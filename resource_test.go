@@ -0,0 +1,128 @@
+package jac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/jsonapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID   string `jsonapi:"primary,users"`
+	Name string `jsonapi:"attr,name"`
+}
+
+// newUsersServer returns in-memory JSON:API users service
+func newUsersServer() *httptest.Server {
+	var (
+		mu    sync.Mutex
+		users = map[string]*testUser{"a/b": {ID: "a/b", Name: "slashed"}}
+	)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", jsonapi.MediaType)
+
+		id := r.URL.Path[len("/users"):]
+		if id != "" {
+			id = id[1:]
+		}
+
+		switch {
+		case strings.Contains(id, "/") && r.URL.RawPath == "":
+			// id was not escaped by the client
+			w.WriteHeader(http.StatusBadRequest)
+		case r.Method == http.MethodGet && id == "":
+			var list []*testUser
+			for _, user := range users {
+				if name := r.URL.Query().Get("filter[name]"); name == "" || name == user.Name {
+					list = append(list, user)
+				}
+			}
+			_ = jsonapi.MarshalPayload(w, list)
+		case r.Method == http.MethodPost && id == "":
+			user := new(testUser)
+			_ = jsonapi.UnmarshalPayload(r.Body, user)
+			user.ID = user.Name
+			users[user.ID] = user
+			w.WriteHeader(http.StatusCreated)
+			_ = jsonapi.MarshalPayload(w, user)
+		case users[id] == nil:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet:
+			_ = jsonapi.MarshalPayload(w, users[id])
+		case r.Method == http.MethodPatch:
+			patch := new(testUser)
+			_ = jsonapi.UnmarshalPayload(r.Body, patch)
+			if patch.ID != id {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			users[id].Name = patch.Name
+			_ = jsonapi.MarshalPayload(w, users[id])
+		case r.Method == http.MethodDelete:
+			delete(users, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func TestResource(t *testing.T) {
+	testServer := newUsersServer()
+	defer testServer.Close()
+
+	var (
+		ctx   = context.Background()
+		users = NewResource[testUser](NewJac(testServer.URL))
+	)
+
+	t.Run("create", func(t *testing.T) {
+		user, err := users.Create(ctx, &testUser{Name: "alice"})
+		assert.Nil(t, err)
+		assert.Equal(t, &testUser{ID: "alice", Name: "alice"}, user)
+	})
+	t.Run("get with escaped id", func(t *testing.T) {
+		user, err := users.Get(ctx, "a/b")
+		assert.Nil(t, err)
+		assert.Equal(t, &testUser{ID: "a/b", Name: "slashed"}, user)
+	})
+	t.Run("list", func(t *testing.T) {
+		list, err := users.List(ctx, Query().Filter("name", "alice").Values())
+		assert.Nil(t, err)
+		assert.Equal(t, []*testUser{{ID: "alice", Name: "alice"}}, list)
+	})
+	t.Run("update", func(t *testing.T) {
+		patch := &testUser{Name: "alicia"}
+		user, err := users.Update(ctx, "alice", patch)
+		assert.Nil(t, err)
+		assert.Equal(t, &testUser{ID: "alice", Name: "alicia"}, user)
+		assert.Equal(t, &testUser{Name: "alicia"}, patch, "expected patch not to be modified")
+	})
+	t.Run("delete", func(t *testing.T) {
+		assert.Nil(t, users.Delete(ctx, "alice"))
+
+		_, err := users.Get(ctx, "alice")
+		assert.True(t, IsNotFound(err))
+	})
+	t.Run("custom endpoint", func(t *testing.T) {
+		_, err := users.At("v2/users").Get(ctx, "a/b")
+		assert.True(t, IsNotFound(err))
+	})
+}
+
+func TestNewResource_NoPrimaryTag(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic")
+		}
+	}()
+
+	_ = NewResource[getTestResponse](NewJac("http://localhost"))
+}
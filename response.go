@@ -0,0 +1,64 @@
+package jac
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Response is a response of an API together with its metadata
+type Response struct {
+	// StatusCode is an HTTP status code of the response
+	StatusCode int
+	// Header contains response headers, e.g. Location, ETag or rate limits
+	Header http.Header
+	// Body is a raw response body
+	Body []byte
+	// Duration is how long the request took, including retries and body read
+	Duration time.Duration
+	// URL is a final URL of the request, after redirects were followed
+	URL *url.URL
+	// RequestID is the request id echoed by the server or,
+	// if it has not echoed any, the one that was sent
+	RequestID string
+	// Attempts is a number of attempts made, more than 1 if the request was retried
+	Attempts int
+	// Timing is a breakdown of the last attempt, which is nil
+	// unless timing is enabled with WithTiming
+	Timing *Timing
+}
+
+// Decode unmarshals response body into destination
+func (r *Response) Decode(destination any) error {
+	return json.Unmarshal(r.Body, &destination)
+}
+
+// DecodeResource unmarshals JSON:API document with a single resource
+// into model, which must be a pointer to a struct with jsonapi tags
+func (r *Response) DecodeResource(model any) (*Document, error) {
+	return decodeResource(r.Body, model)
+}
+
+// DecodeCollection unmarshals JSON:API document with a collection of resources
+// into models, which must be a pointer to a slice of pointers to structs with jsonapi tags
+func (r *Response) DecodeCollection(models any) (*Document, error) {
+	return decodeCollection(r.Body, models)
+}
+
+// APIError returns APIError if the response has status code
+// equal or higher than 400 and nil otherwise
+func (r *Response) APIError() *APIError {
+	if r.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	return &APIError{
+		StatusCode: r.StatusCode,
+		Header:     r.Header,
+		Body:       r.Body,
+		RequestID:  r.RequestID,
+		Attempts:   r.Attempts,
+		Errors:     decodeErrors(r.StatusCode, r.Header, r.Body),
+	}
+}
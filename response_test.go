@@ -0,0 +1,48 @@
+package jac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJacer_Do(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/users/1")
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	})
+	mux.Handle("/old-users", http.RedirectHandler("/users", http.StatusTemporaryRedirect))
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	testJac := NewJac(testServer.URL)
+
+	t.Run("response metadata", func(t *testing.T) {
+		var user struct {
+			ID string `json:"id"`
+		}
+		response, err := testJac.Do(http.MethodPost, RequestParams{Endpoint: "users"}, &user)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+		assert.Equal(t, "/users/1", response.Header.Get("Location"))
+		assert.Equal(t, `"v1"`, response.Header.Get("ETag"))
+		assert.Equal(t, `{"id":"1"}`, string(response.Body))
+		assert.Positive(t, response.Duration)
+		assert.Equal(t, "1", user.ID)
+	})
+	t.Run("final url after redirect", func(t *testing.T) {
+		response, err := testJac.Do(http.MethodPost, RequestParams{Endpoint: "old-users"}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, testServer.URL+"/users", response.URL.String())
+	})
+	t.Run("error response", func(t *testing.T) {
+		response, err := testJac.Do(http.MethodGet, RequestParams{Endpoint: "missing"}, nil)
+		assert.True(t, IsNotFound(err))
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+}
@@ -0,0 +1,280 @@
+package jac
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorClass is a kind of error that happened while sending a request
+type ErrorClass string
+
+const (
+	// ErrorClassTimeout is a network or client timeout
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassConnection is a failure to establish or keep a connection
+	ErrorClassConnection ErrorClass = "connection"
+	// ErrorClassCanceled is a cancelled or expired request context
+	ErrorClassCanceled ErrorClass = "canceled"
+	// ErrorClassOther is any other error
+	ErrorClassOther ErrorClass = "other"
+)
+
+// ClassifyError returns a class of error returned while sending a request.
+// Without the request context an expired deadline cannot be told from
+// a client timeout, so both are classed as timeouts
+func ClassifyError(err error) ErrorClass {
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout(), errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorClassConnection
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrorClassConnection
+	}
+
+	return ErrorClassOther
+}
+
+// classifyCallError returns a class of error call failed with. It is canceled
+// only if the caller context is done, so client timeouts, which expire the
+// internal context of http.Client, are classed as timeouts
+func classifyCallError(call *Call, err error) ErrorClass {
+	if call.Request.Context().Err() != nil {
+		return ErrorClassCanceled
+	}
+	if class := ClassifyError(err); class != ErrorClassCanceled {
+		return class
+	}
+
+	return ErrorClassOther
+}
+
+// RetryPolicy describes when and how failed requests are retried.
+// Zero fields are replaced with the ones from DefaultRetryPolicy.
+// Request body is replayed on every attempt
+type RetryPolicy struct {
+	// MaxAttempts is a maximum number of attempts including the first one
+	MaxAttempts int
+	// BaseBackoff is a backoff before the second attempt.
+	// It is doubled on every next attempt
+	BaseBackoff time.Duration
+	// MaxBackoff limits the backoff between attempts. A call is not retried
+	// if the server asks with Retry-After to wait longer than that
+	MaxBackoff time.Duration
+	// Statuses are response status codes that are retried
+	Statuses []int
+	// Methods are request methods that are retried
+	Methods []string
+	// Errors are classes of errors that are retried
+	Errors []ErrorClass
+}
+
+// DefaultRetryPolicy returns a policy that makes up to 3 attempts of GET, HEAD,
+// OPTIONS and DELETE requests failed with network errors or 429, 502, 503 and 504 statuses
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: 100 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+		Statuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		Methods: []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete},
+		Errors:  []ErrorClass{ErrorClassTimeout, ErrorClassConnection},
+	}
+}
+
+// RetryError is returned when a request has failed after several attempts
+type RetryError struct {
+	// Attempts is a number of attempts made
+	Attempts int
+	// Err is an error of the last attempt
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("failed after %d attempts: %s", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// RetryMiddleware returns Middleware that retries failed calls according
+// to policy. Every attempt is sent with a copy of the call, so middlewares
+// it wraps see every attempt with Call.Attempt set
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	policy = policy.withDefaults()
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			for attempt := 1; ; attempt++ {
+				attemptCall, err := call.Clone()
+				if err != nil {
+					return nil, err
+				}
+				attemptCall.Attempt = attempt
+				if call.attempts != nil {
+					*call.attempts = attempt
+				}
+
+				response, err := next.Do(attemptCall)
+				backoff, ok := policy.backoff(attempt, response)
+				if !ok || !policy.shouldRetry(attemptCall, response, err) {
+					if err != nil && attempt > 1 {
+						return nil, &RetryError{Attempts: attempt, Err: err}
+					}
+					return response, err
+				}
+
+				discardResponse(response)
+
+				if err = sleep(call.Request.Context(), backoff); err != nil {
+					return nil, &RetryError{Attempts: attempt, Err: err}
+				}
+			}
+		})
+	}
+}
+
+// withDefaults replaces zero fields of policy with default ones
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = defaults.MaxAttempts
+	}
+	if p.BaseBackoff == 0 {
+		p.BaseBackoff = defaults.BaseBackoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = defaults.MaxBackoff
+	}
+	if p.Statuses == nil {
+		p.Statuses = defaults.Statuses
+	}
+	if p.Methods == nil {
+		p.Methods = defaults.Methods
+	}
+	if p.Errors == nil {
+		p.Errors = defaults.Errors
+	}
+
+	return p
+}
+
+// shouldRetry reports whether the attempt that ended with given response or error should be retried
+func (p RetryPolicy) shouldRetry(call *Call, response *http.Response, err error) bool {
+	if call.Attempt >= p.MaxAttempts || !contains(p.Methods, call.Request.Method) {
+		return false
+	}
+
+	if err != nil {
+		return contains(p.Errors, classifyCallError(call, err))
+	}
+
+	return contains(p.Statuses, response.StatusCode)
+}
+
+// backoff returns how long to wait before the next attempt. Retry-After header
+// is honoured for 429 and 503 responses, otherwise exponential backoff with
+// full jitter is used. It reports false if Retry-After exceeds MaxBackoff,
+// so the call should not be retried
+func (p RetryPolicy) backoff(attempt int, response *http.Response) (time.Duration, bool) {
+	if response != nil && (response.StatusCode == http.StatusTooManyRequests ||
+		response.StatusCode == http.StatusServiceUnavailable) {
+		if wait, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			return wait, wait <= p.MaxBackoff
+		}
+	}
+
+	backoff := p.BaseBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1)), true
+}
+
+// parseRetryAfter parses Retry-After header value which is either
+// a number of seconds or an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := time.Until(date)
+	if wait < 0 {
+		wait = 0
+	}
+
+	return wait, true
+}
+
+// sleep waits for given duration or until ctx is done
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discardResponse drains and closes response body, so the connection can be reused
+func discardResponse(response *http.Response) {
+	if response == nil {
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+}
+
+func contains[T comparable](slice []T, value T) bool {
+	for _, item := range slice {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,177 @@
+package jac
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFlakyServer returns server that fails first failures requests with status
+// and then responds with the request body
+func newFlakyServer(failures int64, status int, header http.Header) (*httptest.Server, *int64) {
+	var hits int64
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&hits, 1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"errors":[{"status":"` + http.StatusText(status) + `"}]}`))
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	})), &hits
+}
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseBackoff: time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+func TestJacer_Retry(t *testing.T) {
+	t.Run("retries idempotent request until success", func(t *testing.T) {
+		server, hits := newFlakyServer(2, http.StatusBadGateway, nil)
+		defer server.Close()
+
+		var response string
+		apiErrs, err := NewJac(server.URL, WithRetryPolicy(testRetryPolicy)).
+			Delete(RequestParams{Body: []byte(`"replayed"`)})
+		assert.Nil(t, err)
+		assert.Empty(t, apiErrs)
+		assert.Equal(t, int64(3), atomic.LoadInt64(hits))
+
+		atomic.StoreInt64(hits, 0)
+		apiErrs, err = NewJac(server.URL, WithRetryPolicy(testRetryPolicy)).
+			Get(RequestParams{Body: []byte(`"replayed"`)}, &response)
+		assert.Nil(t, err)
+		assert.Empty(t, apiErrs)
+		assert.Equal(t, "replayed", response, "expected body to be replayed")
+	})
+	t.Run("returns last response when attempts are exhausted", func(t *testing.T) {
+		server, hits := newFlakyServer(5, http.StatusServiceUnavailable, nil)
+		defer server.Close()
+
+		apiErrs, err := NewJac(server.URL, WithRetryPolicy(testRetryPolicy)).Get(RequestParams{}, nil)
+		assert.Nil(t, err)
+		assert.Len(t, apiErrs, 1)
+		assert.Equal(t, int64(3), atomic.LoadInt64(hits))
+
+		atomic.StoreInt64(hits, 0)
+		response, err := NewJac(server.URL, WithRetryPolicy(testRetryPolicy), WithRequestIDHeader("")).
+			Do(http.MethodGet, RequestParams{}, nil)
+		assert.Equal(t, 3, response.Attempts)
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr), "expected APIError, got %v", err)
+		assert.Equal(t, 3, apiErr.Attempts)
+		assert.ErrorContains(t, err, "api responded with status 503 Service Unavailable after 3 attempts")
+	})
+	t.Run("does not retry non-idempotent request", func(t *testing.T) {
+		server, hits := newFlakyServer(1, http.StatusBadGateway, nil)
+		defer server.Close()
+
+		apiErrs, err := NewJac(server.URL, WithRetryPolicy(testRetryPolicy)).Post(RequestParams{}, nil)
+		assert.Nil(t, err)
+		assert.Len(t, apiErrs, 1)
+		assert.Equal(t, int64(1), atomic.LoadInt64(hits))
+	})
+	t.Run("does not retry without policy", func(t *testing.T) {
+		server, hits := newFlakyServer(1, http.StatusBadGateway, nil)
+		defer server.Close()
+
+		_, _ = NewJac(server.URL).Get(RequestParams{}, nil)
+		assert.Equal(t, int64(1), atomic.LoadInt64(hits))
+	})
+	t.Run("honours Retry-After", func(t *testing.T) {
+		server, hits := newFlakyServer(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+		defer server.Close()
+
+		policy := testRetryPolicy
+		policy.MaxBackoff = 2 * time.Second
+
+		start := time.Now()
+		_, err := NewJac(server.URL, WithRetryPolicy(policy)).Get(RequestParams{}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), atomic.LoadInt64(hits))
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+	t.Run("does not wait for Retry-After longer than MaxBackoff", func(t *testing.T) {
+		server, hits := newFlakyServer(1, http.StatusServiceUnavailable, http.Header{"Retry-After": {"86400"}})
+		defer server.Close()
+
+		start := time.Now()
+		apiErrs, err := NewJac(server.URL, WithRetryPolicy(testRetryPolicy)).Get(RequestParams{}, nil)
+		assert.Nil(t, err)
+		assert.Len(t, apiErrs, 1)
+		assert.Equal(t, int64(1), atomic.LoadInt64(hits))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+	t.Run("retries client timeouts", func(t *testing.T) {
+		var hits int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt64(&hits, 1) == 1 {
+				time.Sleep(200 * time.Millisecond)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		_, err := NewJac(server.URL, WithTimeout(50*time.Millisecond), WithRetryPolicy(testRetryPolicy)).Get(RequestParams{}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), atomic.LoadInt64(&hits))
+	})
+	t.Run("does not retry cancelled request", func(t *testing.T) {
+		var hits int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&hits, 1)
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := NewJac(server.URL, WithRetryPolicy(testRetryPolicy)).Get(RequestParams{Context: ctx}, nil)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected deadline exceeded error, got %v", err)
+		assert.Equal(t, int64(1), atomic.LoadInt64(&hits))
+	})
+	t.Run("reports attempts on connection errors", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		_, err := NewJac(server.URL, WithRetryPolicy(testRetryPolicy)).Get(RequestParams{}, nil)
+
+		var retryErr *RetryError
+		assert.True(t, errors.As(err, &retryErr), "expected RetryError, got %v", err)
+		assert.Equal(t, 3, retryErr.Attempts)
+		assert.Equal(t, ErrorClassConnection, ClassifyError(err))
+	})
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}.withDefaults()
+
+	for attempt := 1; attempt < 10; attempt++ {
+		backoff, ok := policy.backoff(attempt, nil)
+		assert.True(t, ok)
+		assert.LessOrEqual(t, backoff, 50*time.Millisecond)
+	}
+
+	wait, ok := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, float64(time.Minute), float64(wait), float64(2*time.Second))
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
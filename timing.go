@@ -0,0 +1,123 @@
+package jac
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing is a breakdown of a single attempt of a call captured with
+// httptrace. Phases that did not happen, e.g. DNS lookup and connect on
+// a reused connection, are zero
+type Timing struct {
+	// DNS is how long DNS lookup took
+	DNS time.Duration
+	// Connect is how long establishing of a TCP connection took
+	Connect time.Duration
+	// TLSHandshake is how long TLS handshake took
+	TLSHandshake time.Duration
+	// TimeToFirstByte is a time from the start of the attempt till the
+	// first byte of the response, so it includes all the phases above
+	TimeToFirstByte time.Duration
+	// BodyRead is a time from receiving response headers till the
+	// response body was closed
+	BodyRead time.Duration
+	// ConnReused reports whether a connection was taken from the idle pool
+	ConnReused bool
+}
+
+// timingTrace collects timestamps of httptrace events. Events may come from
+// other goroutines, even after the response is received, so it is guarded
+type timingTrace struct {
+	mu sync.Mutex
+
+	start                      time.Time
+	dnsStart, dnsDone          time.Time
+	connectStart, connectDone  time.Time
+	tlsStart, tlsDone          time.Time
+	firstByte, headersReceived time.Time
+	reused                     bool
+}
+
+// clientTrace returns httptrace hooks recording into t
+func (t *timingTrace) clientTrace() *httptrace.ClientTrace {
+	record := func(at *time.Time, keepFirst bool) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if keepFirst && !at.IsZero() {
+			return
+		}
+		*at = time.Now()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { record(&t.dnsStart, true) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { record(&t.dnsDone, false) },
+		// parallel dials of dual-stack hosts are counted from the first start to the last end
+		ConnectStart:         func(string, string) { record(&t.connectStart, true) },
+		ConnectDone:          func(string, string, error) { record(&t.connectDone, false) },
+		TLSHandshakeStart:    func() { record(&t.tlsStart, true) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone, false) },
+		GotFirstResponseByte: func() { record(&t.firstByte, true) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+		},
+	}
+}
+
+// timing returns the breakdown collected so far. The body read time
+// is counted till end, if headers were received
+func (t *timingTrace) timing(end time.Time) Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timing := Timing{
+		DNS:             between(t.dnsStart, t.dnsDone),
+		Connect:         between(t.connectStart, t.connectDone),
+		TLSHandshake:    between(t.tlsStart, t.tlsDone),
+		TimeToFirstByte: between(t.start, t.firstByte),
+		ConnReused:      t.reused,
+	}
+	if !t.headersReceived.IsZero() {
+		timing.BodyRead = between(t.headersReceived, end)
+	}
+
+	return timing
+}
+
+// between returns a duration from start to end or zero if any of them is unknown
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+
+	return end.Sub(start)
+}
+
+// timedDo sends call with client capturing its Timing into call.Timing,
+// which is updated again when the response body is closed
+func timedDo(client *http.Client, call *Call) (*http.Response, error) {
+	trace := &timingTrace{start: time.Now()}
+	request := call.Request.WithContext(httptrace.WithClientTrace(call.Request.Context(), trace.clientTrace()))
+
+	response, err := client.Do(request)
+	if err != nil {
+		*call.Timing = trace.timing(time.Time{})
+		return nil, err
+	}
+
+	trace.mu.Lock()
+	trace.headersReceived = time.Now()
+	trace.mu.Unlock()
+	*call.Timing = trace.timing(time.Time{})
+
+	observeBody(response, func(int64) {
+		*call.Timing = trace.timing(time.Now())
+	})
+
+	return response, nil
+}
@@ -0,0 +1,75 @@
+package jac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJacer_Timing(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":`))
+		w.(http.Flusher).Flush()
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`null}`))
+	}))
+	defer testServer.Close()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		response, err := NewJac(testServer.URL, WithHTTPClient(testServer.Client())).Do(http.MethodGet, RequestParams{}, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, response.Timing)
+	})
+
+	log, buffer := newTestLog()
+	metrics := NewMetrics()
+	testJac := NewJac(testServer.URL,
+		WithHTTPClient(&http.Client{Transport: testServer.Client().Transport.(*http.Transport).Clone()}),
+		WithTiming(),
+		WithLogger(log, LogSettings{}),
+		WithMetrics(metrics, "users"),
+	)
+
+	response, err := testJac.Do(http.MethodGet, RequestParams{}, nil)
+	assert.Nil(t, err)
+	if assert.NotNil(t, response.Timing) {
+		assert.False(t, response.Timing.ConnReused)
+		assert.NotZero(t, response.Timing.Connect)
+		assert.NotZero(t, response.Timing.TLSHandshake)
+		assert.True(t, response.Timing.TimeToFirstByte >= response.Timing.Connect+response.Timing.TLSHandshake)
+		assert.True(t, response.Timing.BodyRead >= 10*time.Millisecond)
+	}
+
+	response, err = testJac.Do(http.MethodGet, RequestParams{}, nil)
+	assert.Nil(t, err)
+	if assert.NotNil(t, response.Timing) {
+		assert.True(t, response.Timing.ConnReused)
+		assert.Zero(t, response.Timing.Connect)
+		assert.Zero(t, response.Timing.TLSHandshake)
+		assert.NotZero(t, response.Timing.TimeToFirstByte)
+	}
+
+	entries := logEntries(t, buffer)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, false, entries[0]["conn_reused"])
+	assert.Equal(t, true, entries[1]["conn_reused"])
+	assert.NotNil(t, entries[0]["tls_handshake"])
+	assert.NotNil(t, entries[0]["body_read"])
+
+	exposed := metrics.expose()
+	assert.Contains(t, exposed, `jac_request_phase_duration_seconds_count{connector="users",phase="connect"} 1`)
+	assert.Contains(t, exposed, `jac_request_phase_duration_seconds_count{connector="users",phase="tls_handshake"} 1`)
+	assert.Contains(t, exposed, `jac_request_phase_duration_seconds_count{connector="users",phase="body_read"} 2`)
+	assert.Contains(t, exposed, `jac_connections_total{connector="users",reused="false"} 1`)
+	assert.Contains(t, exposed, `jac_connections_total{connector="users",reused="true"} 1`)
+	assert.False(t, strings.Contains(exposed, `phase="dns"`))
+
+	t.Run("transport error", func(t *testing.T) {
+		_, err := NewJac("http://127.0.0.1:1", WithTiming(), WithLogger(log, LogSettings{})).Get(RequestParams{}, nil)
+		assert.NotNil(t, err)
+	})
+}
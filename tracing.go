@@ -0,0 +1,211 @@
+package jac
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// traceparentHeader carries W3C trace context
+	traceparentHeader = "Traceparent"
+	// tracestateHeader carries vendor specific W3C trace state
+	tracestateHeader = "Tracestate"
+	// sampledFlag is a trace flag of sampled traces
+	sampledFlag = 0x01
+)
+
+// SpanContext identifies a span of a W3C trace
+type SpanContext struct {
+	// TraceID is 32 lowercase hex characters
+	TraceID string
+	// SpanID is 16 lowercase hex characters
+	SpanID string
+	// Flags are trace flags, e.g. 01 for sampled traces
+	Flags byte
+	// TraceState is vendor specific trace state passed as is
+	TraceState string
+}
+
+// IsValid reports whether trace and span ids are well-formed and not zero
+func (sc SpanContext) IsValid() bool {
+	return isTraceID(sc.TraceID, 32) && isTraceID(sc.SpanID, 16)
+}
+
+// Traceparent returns traceparent header value of the span context
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent parses traceparent header value
+func ParseTraceparent(traceparent string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, errors.Errorf("malformed traceparent %q", traceparent)
+	}
+
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return SpanContext{}, errors.Errorf("malformed trace flags in traceparent %q", traceparent)
+	}
+
+	sc := SpanContext{TraceID: parts[1], SpanID: parts[2], Flags: flags[0]}
+	if !sc.IsValid() {
+		return SpanContext{}, errors.Errorf("invalid trace or span id in traceparent %q", traceparent)
+	}
+
+	return sc, nil
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span context. Requests
+// sent with this context are traced as children of the span
+func ContextWithSpan(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanFromContext returns span context carried by ctx
+func SpanFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok
+}
+
+// ExtractTraceContext returns a copy of ctx carrying span context from
+// traceparent and tracestate headers, e.g. of an incoming request. If the
+// headers are missing or malformed, ctx is returned as is
+func ExtractTraceContext(ctx context.Context, header http.Header) context.Context {
+	sc, err := ParseTraceparent(header.Get(traceparentHeader))
+	if err != nil {
+		return ctx
+	}
+	sc.TraceState = header.Get(tracestateHeader)
+
+	return ContextWithSpan(ctx, sc)
+}
+
+// Span is a client span of a single attempt of a call
+type Span struct {
+	// Context identifies the span. Its trace is taken from the request
+	// context or started anew
+	Context SpanContext
+	// ParentSpanID is a span id from the request context, if any
+	ParentSpanID string
+	// Method is the request method
+	Method string
+	// Endpoint is the endpoint template
+	Endpoint string
+	// Attempt is a number of the attempt starting from 1
+	Attempt int
+	// StatusCode is the response status, zero if there is no response
+	StatusCode int
+	// Err is the error the attempt failed with, if any
+	Err error
+	// Start is when the attempt started
+	Start time.Time
+	// End is when the response body was closed or the attempt failed.
+	// It is zero in SpanStarted
+	End time.Time
+}
+
+// SpanRecorder receives client spans, so they can be exported to any
+// tracing backend. Methods are called synchronously, so they must not block
+type SpanRecorder interface {
+	// SpanStarted is called before an attempt is sent
+	SpanStarted(span Span)
+	// SpanEnded is called when an attempt has finished
+	SpanEnded(span Span)
+}
+
+// TracingMiddleware returns Middleware that sends traceparent and
+// tracestate headers with every attempt. Every attempt is a child span
+// of the span from the request context, whose sampling decision is kept.
+// If there is none, a new unsampled trace is started, so untraced calls do
+// not force sampling downstream. The attempt span is put into the request
+// context, and it is reported to recorder unless it is nil
+func TracingMiddleware(recorder SpanRecorder) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(call *Call) (*http.Response, error) {
+			parent, hasParent := SpanFromContext(call.Request.Context())
+
+			span := Span{
+				Context:  SpanContext{TraceID: parent.TraceID, Flags: parent.Flags, TraceState: parent.TraceState},
+				Method:   call.Request.Method,
+				Endpoint: call.Endpoint,
+				Attempt:  call.Attempt,
+				Start:    time.Now(),
+			}
+			if hasParent && parent.IsValid() {
+				span.ParentSpanID = parent.SpanID
+			} else {
+				span.Context = SpanContext{TraceID: randomHex(16)}
+			}
+			span.Context.SpanID = randomHex(8)
+
+			traced := *call
+			traced.Request = call.Request.WithContext(ContextWithSpan(call.Request.Context(), span.Context))
+			traced.Request.Header = call.Request.Header.Clone()
+			traced.Request.Header.Set(traceparentHeader, span.Context.Traceparent())
+			if span.Context.TraceState != "" {
+				traced.Request.Header.Set(tracestateHeader, span.Context.TraceState)
+			}
+
+			if recorder != nil {
+				recorder.SpanStarted(span)
+			}
+
+			response, err := next.Do(&traced)
+			if err != nil {
+				if recorder != nil {
+					span.Err, span.End = err, time.Now()
+					recorder.SpanEnded(span)
+				}
+				return nil, err
+			}
+
+			if recorder != nil {
+				span.StatusCode = response.StatusCode
+				observeBody(response, func(int64) {
+					span.End = time.Now()
+					recorder.SpanEnded(span)
+				})
+			}
+
+			return response, nil
+		})
+	}
+}
+
+// isTraceID reports whether id is a non-zero lowercase hex string of given length
+func isTraceID(id string, length int) bool {
+	if len(id) != length || strings.Trim(id, "0") == "" {
+		return false
+	}
+
+	for _, r := range id {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// randomHex returns size random bytes encoded as hex, which are never all zero
+func randomHex(size int) string {
+	id := make([]byte, size)
+	for {
+		if _, err := rand.Read(id); err != nil {
+			panic(errors.Wrap(err, "failed to read random bytes"))
+		}
+		if encoded := hex.EncodeToString(id); strings.Trim(encoded, "0") != "" {
+			return encoded
+		}
+	}
+}
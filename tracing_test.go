@@ -0,0 +1,130 @@
+package jac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSpanRecorder keeps spans it receives
+type testSpanRecorder struct {
+	mu      sync.Mutex
+	started []Span
+	ended   []Span
+}
+
+func (r *testSpanRecorder) SpanStarted(span Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, span)
+}
+
+func (r *testSpanRecorder) SpanEnded(span Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ended = append(r.ended, span)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Nil(t, err)
+	assert.Equal(t, SpanContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+		Flags:   sampledFlag,
+	}, sc)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+
+	for _, malformed := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err := ParseTraceparent(malformed)
+		assert.NotNil(t, err, malformed)
+	}
+}
+
+func TestJacer_Tracing(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		headers []http.Header
+	)
+	// fails the first request to make the connector retry it
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		first := len(headers) == 1
+		mu.Unlock()
+
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer testServer.Close()
+
+	recorder := &testSpanRecorder{}
+	testJac := NewJac(testServer.URL, WithTracing(recorder), WithRetryPolicy(testRetryPolicy))
+
+	incoming := http.Header{}
+	incoming.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	incoming.Set("Tracestate", "vendor=value")
+	ctx := ExtractTraceContext(context.Background(), incoming)
+
+	_, err := testJac.Get(RequestParams{Endpoint: "users/{id}", PathParams: map[string]string{"id": "1"}, Context: ctx}, nil)
+	assert.Nil(t, err)
+
+	assert.Len(t, headers, 2)
+	assert.Len(t, recorder.started, 2)
+	assert.Len(t, recorder.ended, 2)
+
+	for i, span := range recorder.ended {
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.Context.TraceID)
+		assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID)
+		assert.Equal(t, http.MethodGet, span.Method)
+		assert.Equal(t, "users/{id}", span.Endpoint)
+		assert.Equal(t, i+1, span.Attempt)
+		assert.False(t, span.End.IsZero())
+
+		assert.Equal(t, span.Context.Traceparent(), headers[i].Get("Traceparent"))
+		assert.Equal(t, "vendor=value", headers[i].Get("Tracestate"))
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.ended[0].StatusCode)
+	assert.Equal(t, http.StatusOK, recorder.ended[1].StatusCode)
+	assert.NotEqual(t, recorder.ended[0].Context.SpanID, recorder.ended[1].Context.SpanID)
+
+	t.Run("new trace", func(t *testing.T) {
+		recorder := &testSpanRecorder{}
+		_, err := NewJac("http://127.0.0.1:1", WithTracing(recorder)).Get(RequestParams{}, nil)
+		assert.NotNil(t, err)
+
+		assert.Len(t, recorder.ended, 1)
+		assert.True(t, recorder.ended[0].Context.IsValid())
+		assert.Empty(t, recorder.ended[0].ParentSpanID)
+		assert.NotNil(t, recorder.ended[0].Err)
+	})
+	t.Run("new trace is not sampled", func(t *testing.T) {
+		mu.Lock()
+		headers = nil
+		mu.Unlock()
+
+		recorder := &testSpanRecorder{}
+		_, err := NewJac(testServer.URL, WithTracing(recorder)).Get(RequestParams{}, nil)
+		assert.Nil(t, err)
+
+		assert.Len(t, headers, 1)
+		assert.Len(t, recorder.ended, 1)
+		assert.Equal(t, byte(0), recorder.ended[0].Context.Flags)
+		assert.True(t, strings.HasSuffix(headers[0].Get("Traceparent"), "-00"), "expected unsampled traceparent, got %q", headers[0].Get("Traceparent"))
+	})
+}